@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// apiKeyNameFlag et apiKeyScopesFlag stockent les valeurs des flags --name et --scopes de 'apikey create'
+var apiKeyNameFlag, apiKeyScopesFlag string
+
+// apiKeyOwnerFlag stocke la valeur du flag --owner de 'apikey create'
+var apiKeyOwnerFlag string
+
+// apiKeyIDFlag stocke la valeur du flag --id de 'apikey revoke'
+var apiKeyIDFlag uint
+
+// APIKeyCmd représente la commande 'apikey', qui regroupe la gestion des clés d'API
+var APIKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Gère les clés d'accès à l'API (create, list, revoke).",
+	Long: `Les clés d'API sont exigées sur /api/v1 lorsque auth.enabled vaut true dans la configuration.
+Elles sont transmises dans l'en-tête "Authorization: Bearer <clé>".
+
+Permissions disponibles :
+  links:write : créer, modifier et supprimer des liens
+  links:read  : lister les liens et consulter leur disponibilité
+  stats:read  : consulter les statistiques de clics
+  admin       : toutes les permissions
+
+Une clé rattachée à un utilisateur (--owner) ne voit que les liens de cet utilisateur,
+et les liens qu'elle crée lui appartiennent. Une clé admin voit tous les liens.`,
+}
+
+// APIKeyCreateCmd représente la commande 'apikey create'
+var APIKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Crée une clé d'API et l'affiche (une seule fois).",
+	Long: `Cette commande crée une clé d'API portant les permissions demandées.
+La clé n'est affichée qu'une fois : seule son empreinte est conservée en base.
+
+Exemple:
+  url-shortener apikey create --name="cms" --scopes="links:write,links:read" --owner="marketing"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKeyNameFlag == "" || apiKeyScopesFlag == "" {
+			log.Fatalf("FATAL: Les flags --name et --scopes sont requis")
+		}
+		scopes, err := services.ParseScopes(apiKeyScopesFlag)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+
+		apiKeyService, db, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		var userID *uint
+		if apiKeyOwnerFlag != "" {
+			userID = resolveOwner(db, apiKeyOwnerFlag)
+		}
+
+		key, plain, err := apiKeyService.CreateAPIKey(apiKeyNameFlag, scopes, userID)
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors de la création de la clé d'API: %v", err)
+		}
+
+		fmt.Printf("Clé d'API créée avec succès:\n")
+		fmt.Printf("ID: %d\n", key.ID)
+		fmt.Printf("Nom: %s\n", key.Name)
+		fmt.Printf("Permissions: %s\n", strings.Join(key.ScopeList(), ", "))
+		if apiKeyOwnerFlag != "" && apiKeyOwnerFlag != noOwner {
+			fmt.Printf("Utilisateur: %s\n", apiKeyOwnerFlag)
+		}
+		fmt.Printf("Clé: %s\n", plain)
+		fmt.Println("Conservez cette clé en lieu sûr : elle ne sera plus affichée.")
+	},
+}
+
+// APIKeyListCmd représente la commande 'apikey list'
+var APIKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Affiche les clés d'API (sans leur valeur).",
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeyService, db, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		keys, err := apiKeyService.ListAPIKeys()
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		userNames := listUserNames(db)
+		if len(keys) == 0 {
+			fmt.Println("Aucune clé d'API. Créez-en une avec: url-shortener apikey create --name=... --scopes=...")
+			return
+		}
+
+		fmt.Printf("Clés d'API (%d):\n\n", len(keys))
+		for _, key := range keys {
+			fmt.Printf("%d. %s (%s...)\n", key.ID, key.Name, key.Prefix)
+			fmt.Printf("   Permissions: %s\n", strings.Join(key.ScopeList(), ", "))
+			if key.UserID != nil {
+				fmt.Printf("   Utilisateur: %s\n", userNames[*key.UserID])
+			}
+			fmt.Printf("   Créée le: %s\n", key.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+			if key.LastUsedAt != nil {
+				fmt.Printf("   Dernière utilisation: %s\n", key.LastUsedAt.Local().Format("2006-01-02 15:04:05"))
+			}
+			if key.IsRevoked() {
+				fmt.Printf("   Statut: RÉVOQUÉE le %s\n\n", key.RevokedAt.Local().Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("   Statut: ACTIVE\n\n")
+			}
+		}
+	},
+}
+
+// APIKeyRevokeCmd représente la commande 'apikey revoke'
+var APIKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Révoque une clé d'API.",
+	Long: `Cette commande révoque une clé d'API : elle est refusée dès la requête suivante.
+L'ID de la clé est affiché par 'apikey list'.
+
+Exemple:
+  url-shortener apikey revoke --id=3`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKeyIDFlag == 0 {
+			log.Fatalf("FATAL: Le flag --id est requis")
+		}
+
+		apiKeyService, _, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		if err := apiKeyService.RevokeAPIKey(apiKeyIDFlag); err != nil {
+			var notFoundErr *customerrors.ErrAPIKeyNotFound
+			if errors.As(err, &notFoundErr) {
+				log.Fatalf("FATAL: %v", notFoundErr)
+			}
+			log.Fatalf("FATAL: Erreur lors de la révocation de la clé d'API: %v", err)
+		}
+		fmt.Printf("Clé d'API %d révoquée.\n", apiKeyIDFlag)
+	},
+}
+
+// openAPIKeyService ouvre la base de données et retourne le service des clés d'API,
+// avec la connexion et la fonction qui la ferme.
+func openAPIKeyService() (*services.APIKeyService, *gorm.DB, func()) {
+	db, closeDB := openDB()
+	return services.NewAPIKeyService(repository.NewAPIKeyRepository(db)), db, closeDB
+}
+
+func init() {
+	APIKeyCreateCmd.Flags().StringVar(&apiKeyNameFlag, "name", "", "Nom de la clé, pour la reconnaître (requis)")
+	APIKeyCreateCmd.Flags().StringVar(&apiKeyScopesFlag, "scopes", "", "Permissions séparées par des virgules : links:write, links:read, stats:read, admin (requis)")
+	APIKeyCreateCmd.Flags().StringVar(&apiKeyOwnerFlag, "owner", "", "Utilisateur pour le compte duquel agit la clé (défaut : liens sans propriétaire)")
+	APIKeyCreateCmd.MarkFlagRequired("name")
+	APIKeyCreateCmd.MarkFlagRequired("scopes")
+
+	APIKeyRevokeCmd.Flags().UintVar(&apiKeyIDFlag, "id", 0, "ID de la clé à révoquer (requis)")
+	APIKeyRevokeCmd.MarkFlagRequired("id")
+
+	// Ajouter les sous-commandes à 'apikey', puis 'apikey' à RootCmd
+	APIKeyCmd.AddCommand(APIKeyCreateCmd, APIKeyListCmd, APIKeyRevokeCmd)
+	cmd2.RootCmd.AddCommand(APIKeyCmd)
+}
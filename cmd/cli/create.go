@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/url" // Pour valider le format de l'URL
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/customerrors"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
@@ -16,14 +19,38 @@ import (
 // longURLFlag stocke la valeur du flag --url
 var longURLFlag string
 
+// aliasFlag stocke la valeur du flag --alias (optionnel)
+var aliasFlag string
+
+// ttlFlag stocke la valeur du flag --ttl (durée de vie du lien, optionnelle)
+var ttlFlag time.Duration
+
+// expiresAtFlag stocke la valeur du flag --expires-at (date d'expiration RFC 3339, optionnelle)
+var expiresAtFlag string
+
+// createOwnerFlag stocke la valeur du flag --owner (utilisateur propriétaire, optionnel)
+var createOwnerFlag string
+
+// dedupeFlag stocke la valeur du flag --dedupe (links.dedupe_default par défaut)
+var dedupeFlag bool
+
 // CreateCmd représente la commande 'create'
 var CreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Crée une URL courte à partir d'une URL longue.",
 	Long: `Cette commande raccourcit une URL longue fournie et affiche le code court généré.
 
-Exemple:
-  url-shortener create --url="https://www.google.com/search?q=go+lang"`,
+Un alias personnalisé peut être proposé avec --alias à la place du code généré.
+Une durée de vie (--ttl) ou une date d'expiration (--expires-at) peut être fixée.
+Le lien peut être attribué à un utilisateur avec --owner (voir 'url-shortener user').
+Avec --dedupe, le lien existant du même propriétaire pour la même URL est affiché au lieu
+d'en créer un nouveau (sans alias ni expiration ; défaut : links.dedupe_default).
+
+Exemples:
+  url-shortener create --url="https://www.google.com/search?q=go+lang"
+  url-shortener create --url="https://www.example.com/soldes" --alias="soldes-ete"
+  url-shortener create --url="https://www.example.com/download" --ttl=72h
+  url-shortener create --url="https://www.example.com/promo" --expires-at="2025-12-31T23:59:59Z"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Valider que le flag --url a été fourni.
 		if longURLFlag == "" {
@@ -62,18 +89,66 @@ Exemple:
 
 		// Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		codeGen, err := services.NewCodeGenerator(services.CodeGeneratorOptions{
+			Strategy: cfg.ShortCode.Strategy,
+			Length:   cfg.ShortCode.Length,
+			Alphabet: cfg.ShortCode.Alphabet,
+			Salt:     cfg.ShortCode.Salt,
+		}, linkRepo)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration des codes courts invalide: %v", err)
+		}
+		linkService := services.NewLinkService(linkRepo, codeGen)
 
 		// Appeler le LinkService et la fonction CreateLink pour créer le lien court.
-		link, err := linkService.CreateLink(longURLFlag)
+		opts := services.CreateLinkOptions{Alias: aliasFlag, TTL: ttlFlag}
+		if expiresAtFlag != "" {
+			expiresAt, err := time.Parse(time.RFC3339, expiresAtFlag)
+			if err != nil {
+				log.Fatalf("FATAL: Date d'expiration invalide (format RFC 3339 attendu): %v", err)
+			}
+			opts.ExpiresAt = &expiresAt
+		}
+		if createOwnerFlag != "" {
+			opts.OwnerID = resolveOwner(db, createOwnerFlag)
+		}
+		opts.Dedupe = cfg.Links.DedupeDefault
+		if cmd.Flags().Changed("dedupe") {
+			opts.Dedupe = dedupeFlag
+		}
+
+		link, created, err := linkService.CreateLink(longURLFlag, opts)
 		if err != nil {
+			var invalidExpirationErr *customerrors.ErrInvalidExpiration
+			if errors.As(err, &invalidExpirationErr) {
+				log.Fatalf("FATAL: %v", invalidExpirationErr)
+			}
+			var aliasTakenErr *customerrors.ErrAliasTaken
+			if errors.As(err, &aliasTakenErr) {
+				log.Fatalf("FATAL: %v", aliasTakenErr)
+			}
+			var invalidAliasErr *customerrors.ErrInvalidAlias
+			if errors.As(err, &invalidAliasErr) {
+				log.Fatalf("FATAL: %v", invalidAliasErr)
+			}
+			var collisionErr *customerrors.ErrCodeCollision
+			if errors.As(err, &collisionErr) {
+				log.Fatalf("FATAL: %v, réessayez ou augmentez shortcode.length", collisionErr)
+			}
 			log.Fatalf("FATAL: Erreur lors de la création du lien: %v", err)
 		}
 
 		fullShortURL := fmt.Sprintf("%s/%s", cfg.Server.BaseURL, link.ShortCode)
-		fmt.Printf("URL courte créée avec succès:\n")
+		if created {
+			fmt.Printf("URL courte créée avec succès:\n")
+		} else {
+			fmt.Printf("Un lien existe déjà pour cette URL:\n")
+		}
 		fmt.Printf("Code: %s\n", link.ShortCode)
 		fmt.Printf("URL complète: %s\n", fullShortURL)
+		if link.ExpiresAt != nil {
+			fmt.Printf("Expire le: %s\n", link.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+		}
 	},
 }
 
@@ -83,6 +158,20 @@ func init() {
 	// Définir le flag --url pour la commande create.
 	CreateCmd.Flags().StringVar(&longURLFlag, "url", "", "URL longue à raccourcir (requis)")
 
+	// Définir le flag --alias (optionnel) pour proposer un code court personnalisé.
+	CreateCmd.Flags().StringVar(&aliasFlag, "alias", "", "Alias personnalisé à utiliser comme code court (optionnel)")
+
+	// Définir les flags d'expiration (optionnels et mutuellement exclusifs).
+	CreateCmd.Flags().DurationVar(&ttlFlag, "ttl", 0, "Durée de vie du lien, ex: 24h, 90m (optionnel)")
+	CreateCmd.Flags().StringVar(&expiresAtFlag, "expires-at", "", "Date d'expiration au format RFC 3339, ex: 2025-12-31T23:59:59Z (optionnel)")
+	CreateCmd.MarkFlagsMutuallyExclusive("ttl", "expires-at")
+
+	// Définir le flag --owner (optionnel) pour attribuer le lien à un utilisateur.
+	CreateCmd.Flags().StringVar(&createOwnerFlag, "owner", "", "Nom de l'utilisateur propriétaire du lien (optionnel)")
+
+	// Définir le flag --dedupe (optionnel) pour réutiliser un lien existant vers la même URL.
+	CreateCmd.Flags().BoolVar(&dedupeFlag, "dedupe", false, "Réutiliser le lien existant pour la même URL (défaut : links.dedupe_default)")
+
 	// Marquer le flag comme requis
 	CreateCmd.MarkFlagRequired("url")
 
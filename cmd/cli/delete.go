@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// deleteCodeFlag stocke la valeur du flag --code
+var deleteCodeFlag string
+
+// DeleteCmd représente la commande 'delete'
+var DeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Supprime un lien court.",
+	Long: `Cette commande supprime un lien court : il ne redirige plus les visiteurs.
+La suppression est douce, l'historique des clics du lien est conservé en base.
+
+Exemple:
+  url-shortener delete --code="xyz123"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Valider que le flag --code a été fourni.
+		if deleteCodeFlag == "" {
+			log.Fatalf("FATAL: Le flag --code est requis")
+		}
+
+		// Charger la configuration chargée globalement via cmd.Cfg
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		// Initialiser la connexion à la base de données SQLite.
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+
+		// S'assurer que la connexion est fermée à la fin de l'exécution de la commande
+		defer func() {
+			if err := sqlDB.Close(); err != nil {
+				log.Printf("Erreur lors de la fermeture de la connexion: %v", err)
+			}
+		}()
+
+		// Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo, nil)
+
+		// Appeler le LinkService et la fonction DeleteLink pour supprimer le lien.
+		if err := linkService.DeleteLink(deleteCodeFlag); err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				log.Fatalf("FATAL: Lien non trouvé pour le code: %s", deleteCodeFlag)
+			}
+			log.Fatalf("FATAL: Erreur lors de la suppression du lien: %v", err)
+		}
+
+		fmt.Printf("Lien %s supprimé avec succès.\n", deleteCodeFlag)
+	},
+}
+
+// init() s'exécute automatiquement lors de l'importation du package.
+// Il est utilisé pour définir les flags que cette commande accepte.
+func init() {
+	// Définir le flag --code pour la commande delete.
+	DeleteCmd.Flags().StringVar(&deleteCodeFlag, "code", "", "Code court du lien à supprimer (requis)")
+
+	// Marquer le flag comme requis
+	DeleteCmd.MarkFlagRequired("code")
+
+	// Ajouter la commande à RootCmd
+	cmd2.RootCmd.AddCommand(DeleteCmd)
+}
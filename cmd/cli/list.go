@@ -3,20 +3,44 @@ package cli
 import (
 	"fmt"
 	"log"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// Flags de la commande 'list'
+var (
+	listLimitFlag  int    // --limit : nombre de liens affichés
+	listSortFlag   string // --sort : critère de tri (created_at ou clicks)
+	listOrderFlag  string // --order : sens du tri (desc ou asc)
+	listSearchFlag string // --search : sous-chaîne recherchée dans l'URL longue
+	listSinceFlag  string // --since : date de création minimale
+	listCursorFlag string // --cursor : curseur de la page à afficher
+	listOwnerFlag  string // --owner : utilisateur propriétaire des liens ("-" = sans propriétaire)
+)
+
 // ListCmd représente la commande 'list'
 var ListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "Affiche la liste de tous les liens raccourcis.",
-	Long: `Cette commande affiche tous les liens raccourcis enregistrés dans la base de données
-avec leur code court, leur URL longue et leur date de création.`,
+	Short: "Affiche la liste des liens raccourcis.",
+	Long: `Cette commande affiche les liens raccourcis enregistrés dans la base de données
+avec leur code court, leur URL longue, leur date de création et leur nombre de clics.
+
+Les résultats sont paginés : si d'autres liens sont disponibles, la commande affiche
+le curseur à passer à --cursor pour obtenir la page suivante.
+
+Exemples:
+  url-shortener list
+  url-shortener list --limit=10 --sort=clicks
+  url-shortener list --search="example.com" --since="2025-01-01"
+  url-shortener list --owner="marketing"
+  url-shortener list --owner="-"   (liens sans propriétaire)`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Charger la configuration
 		cfg := cmd2.Cfg
@@ -24,6 +48,22 @@ avec leur code court, leur URL longue et leur date de création.`,
 			log.Fatalf("FATAL: Configuration non chargée")
 		}
 
+		// Construire les options de pagination et de filtrage à partir des flags
+		opts := repository.ListLinksOptions{
+			Limit:  listLimitFlag,
+			Cursor: listCursorFlag,
+			SortBy: listSortFlag,
+			Order:  listOrderFlag,
+			Search: listSearchFlag,
+		}
+		if listSinceFlag != "" {
+			since, err := services.ParseDateTime(listSinceFlag)
+			if err != nil {
+				log.Fatalf("FATAL: Flag --since invalide: %v", err)
+			}
+			opts.CreatedAfter = &since
+		}
+
 		// Initialiser la connexion à la BDD
 		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
 		if err != nil {
@@ -41,33 +81,69 @@ avec leur code court, leur URL longue et leur date de création.`,
 			}
 		}()
 
-		// Initialiser le repository
+		if listOwnerFlag != "" {
+			opts.Owner = repository.OwnedBy(resolveOwner(db, listOwnerFlag))
+		}
+		userNames := listUserNames(db)
+
+		// Initialiser le repository et le service
 		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo, nil)
 
-		// Récupérer tous les liens
-		links, err := linkRepo.GetAllLinks()
+		// Récupérer la page de liens demandée
+		page, err := linkService.ListLinks(opts)
 		if err != nil {
 			log.Fatalf("FATAL: Erreur lors de la récupération des liens: %v", err)
 		}
 
 		// Afficher les résultats
-		if len(links) == 0 {
+		if len(page.Items) == 0 {
 			fmt.Println("Aucun lien trouvé dans la base de données.")
 			return
 		}
 
-		fmt.Printf("Liste des liens (%d total):\n\n", len(links))
-		for i, link := range links {
+		now := time.Now()
+		fmt.Printf("Liste des liens (%d affiché(s)):\n\n", len(page.Items))
+		for i, item := range page.Items {
+			link := item.Link
 			fmt.Printf("%d. Code: %s\n", i+1, link.ShortCode)
 			fmt.Printf("   URL longue: %s\n", link.LongURL)
 			fmt.Printf("   URL courte: %s/%s\n", cfg.Server.BaseURL, link.ShortCode)
-			fmt.Printf("   Créé le: %s\n\n", link.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("   Créé le: %s\n", link.CreatedAt.Format("2006-01-02 15:04:05"))
+			if link.ExpiresAt != nil {
+				fmt.Printf("   Expire le: %s\n", link.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+			}
+			if link.OwnerID != nil {
+				fmt.Printf("   Propriétaire: %s\n", userNames[*link.OwnerID])
+			}
+			fmt.Printf("   Clics: %d\n", item.ClickCount)
+			fmt.Printf("   Statut: %s\n\n", linkStatus(&link, now))
+		}
+
+		if page.NextCursor != "" {
+			fmt.Printf("Page suivante: url-shortener list --cursor=%s (avec les mêmes filtres)\n", page.NextCursor)
 		}
 	},
 }
 
+// linkStatus retourne le statut lisible d'un lien pour l'affichage.
+func linkStatus(link *models.Link, now time.Time) string {
+	if link.IsExpired(now) {
+		return "EXPIRÉ"
+	}
+	return "ACTIF"
+}
+
 func init() {
+	// Définir les flags de pagination, de tri et de filtrage.
+	ListCmd.Flags().IntVar(&listLimitFlag, "limit", 50, "Nombre maximum de liens affichés (max 100)")
+	ListCmd.Flags().StringVar(&listSortFlag, "sort", repository.SortByCreatedAt, "Critère de tri: created_at ou clicks")
+	ListCmd.Flags().StringVar(&listOrderFlag, "order", repository.OrderDesc, "Sens du tri: desc ou asc")
+	ListCmd.Flags().StringVar(&listSearchFlag, "search", "", "Ne garder que les liens dont l'URL longue contient ce texte")
+	ListCmd.Flags().StringVar(&listSinceFlag, "since", "", "Ne garder que les liens créés depuis cette date (YYYY-MM-DD ou RFC 3339)")
+	ListCmd.Flags().StringVar(&listOwnerFlag, "owner", "", "Ne garder que les liens de cet utilisateur (\"-\" = liens sans propriétaire)")
+	ListCmd.Flags().StringVar(&listCursorFlag, "cursor", "", "Curseur de la page à afficher (fourni en fin de page précédente)")
+
 	// Ajouter la commande à RootCmd
 	cmd2.RootCmd.AddCommand(ListCmd)
 }
-
@@ -6,6 +6,8 @@ import (
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
 	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
 	"gorm.io/gorm"
@@ -16,7 +18,7 @@ var MigrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Exécute les migrations de la base de données pour créer ou mettre à jour les tables.",
 	Long: `Cette commande se connecte à la base de données configurée (SQLite)
-et exécute les migrations automatiques de GORM pour créer les tables 'links' et 'clicks'
+et exécute les migrations automatiques de GORM pour créer les tables 'links', 'clicks', 'link_checks', 'api_keys' et 'users'
 basées sur les modèles Go.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Charger la configuration chargée globalement via cmd.Cfg
@@ -42,12 +44,43 @@ basées sur les modèles Go.`,
 			}
 		}()
 
+		// Le nombre de clics des liens est dénormalisé : il faut le calculer si la colonne est nouvelle.
+		recountClicks := !db.Migrator().HasColumn(&models.Link{}, "click_count")
+
 		// Exécuter les migrations automatiques de GORM.
 		// Utilisez db.AutoMigrate() et passez-lui les pointeurs vers tous vos modèles.
-		if err := db.AutoMigrate(&models.Link{}, &models.Click{}); err != nil {
+		if err := db.AutoMigrate(&models.Link{}, &models.Click{}, &models.LinkCheck{}, &models.APIKey{}, &models.User{}); err != nil {
 			log.Fatalf("FATAL: Échec des migrations: %v", err)
 		}
 
+		// Compléter l'empreinte d'URL (déduplication) des liens créés avant son introduction.
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo, nil)
+		filled, err := linkService.BackfillURLHashes()
+		if err != nil {
+			log.Fatalf("FATAL: Échec du calcul des empreintes d'URL: %v", err)
+		}
+		if filled > 0 {
+			fmt.Printf("Empreinte d'URL calculée pour %d lien(s) existant(s).\n", filled)
+		}
+
+		// Compter les clics déjà enregistrés des liens existants.
+		if recountClicks {
+			if err := linkRepo.RecountClicks(); err != nil {
+				log.Fatalf("FATAL: Échec du calcul du nombre de clics des liens: %v", err)
+			}
+			fmt.Println("Nombre de clics des liens existants calculé.")
+		}
+
+		// Enregistrer en UTC les dates de création des liens créés dans un autre fuseau.
+		normalized, err := linkRepo.NormalizeCreatedAt()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la conversion des dates de création: %v", err)
+		}
+		if normalized > 0 {
+			fmt.Printf("Date de création convertie en UTC pour %d lien(s) existant(s).\n", normalized)
+		}
+
 		// Pas touche au log
 		fmt.Println("Migrations de la base de données exécutées avec succès.")
 	},
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// Flags de la commande 'reassign'
+var (
+	reassignCodeFlag  string // --code : code court du lien à transférer
+	reassignFromFlag  string // --from : utilisateur dont tous les liens sont transférés
+	reassignOwnerFlag string // --owner : nouveau propriétaire
+)
+
+// ReassignCmd représente la commande 'reassign'
+var ReassignCmd = &cobra.Command{
+	Use:   "reassign",
+	Short: "Transfère un lien, ou tous les liens d'un utilisateur, à un autre utilisateur.",
+	Long: `Cette commande d'administration change le propriétaire d'un lien (--code)
+ou de tous les liens d'un utilisateur (--from). La valeur "-" désigne l'absence de propriétaire :
+--from="-" transfère les liens sans propriétaire, --owner="-" retire le propriétaire.
+
+Exemples:
+  url-shortener reassign --code="soldes-ete" --owner="marketing"
+  url-shortener reassign --from="alice" --owner="support"
+  url-shortener reassign --from="-" --owner="marketing"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if reassignOwnerFlag == "" {
+			log.Fatalf("FATAL: Le flag --owner est requis")
+		}
+		if (reassignCodeFlag == "") == (reassignFromFlag == "") {
+			log.Fatalf("FATAL: Indiquez soit --code, soit --from")
+		}
+
+		db, closeDB := openDB()
+		defer closeDB()
+
+		ownerID := resolveOwner(db, reassignOwnerFlag)
+		linkService := services.NewLinkService(repository.NewLinkRepository(db), nil)
+
+		if reassignCodeFlag != "" {
+			link, err := linkService.ReassignLink(reassignCodeFlag, ownerID)
+			if err != nil {
+				var notFoundErr *customerrors.ErrLinkNotFound
+				if errors.As(err, &notFoundErr) {
+					log.Fatalf("FATAL: %v", notFoundErr)
+				}
+				log.Fatalf("FATAL: Erreur lors du transfert du lien: %v", err)
+			}
+			fmt.Printf("Lien %s transféré à %s.\n", link.ShortCode, ownerLabel(reassignOwnerFlag))
+			return
+		}
+
+		fromID := resolveOwner(db, reassignFromFlag)
+		count, err := linkService.ReassignLinks(fromID, ownerID)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		fmt.Printf("%d lien(s) de %s transféré(s) à %s.\n", count, ownerLabel(reassignFromFlag), ownerLabel(reassignOwnerFlag))
+	},
+}
+
+// ownerLabel retourne le libellé d'un flag --owner / --from pour l'affichage.
+func ownerLabel(name string) string {
+	if name == noOwner {
+		return "(sans propriétaire)"
+	}
+	return name
+}
+
+func init() {
+	ReassignCmd.Flags().StringVar(&reassignCodeFlag, "code", "", "Code court du lien à transférer")
+	ReassignCmd.Flags().StringVar(&reassignFromFlag, "from", "", "Utilisateur dont tous les liens sont transférés (\"-\" = liens sans propriétaire)")
+	ReassignCmd.Flags().StringVar(&reassignOwnerFlag, "owner", "", "Nouveau propriétaire (\"-\" = aucun) (requis)")
+	ReassignCmd.MarkFlagsMutuallyExclusive("code", "from")
+	ReassignCmd.MarkFlagRequired("owner")
+
+	// Ajouter la commande à RootCmd
+	cmd2.RootCmd.AddCommand(ReassignCmd)
+}
@@ -4,6 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/repository"
@@ -16,6 +20,33 @@ import (
 // shortCodeFlag stocke la valeur du flag --code
 var shortCodeFlag string
 
+// Flags du mode "série temporelle" de la commande 'stats'
+var (
+	statsFromFlag   string // --from : début de la période
+	statsToFlag     string // --to : fin de la période
+	statsByFlag     string // --by : granularité (hour, day, week)
+	statsFormatFlag string // --format : table ou sparkline
+)
+
+// statsIncludeBotsFlag stocke la valeur du flag --include-bots
+var statsIncludeBotsFlag bool
+
+// Flags du mode "référents" de la commande 'stats'
+var (
+	statsReferrersFlag bool // --referrers : affiche le classement des sites référents
+	statsTopFlag       int  // --top : nombre de référents affichés
+)
+
+// granularityLabels traduit les granularités pour l'affichage.
+var granularityLabels = map[string]string{
+	repository.GranularityHour: "heure",
+	repository.GranularityDay:  "jour",
+	repository.GranularityWeek: "semaine",
+}
+
+// sparkLevels sont les caractères utilisés pour dessiner une sparkline, du plus bas au plus haut.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
 // StatsCmd représente la commande 'stats'
 var StatsCmd = &cobra.Command{
 	Use:   "stats",
@@ -23,8 +54,15 @@ var StatsCmd = &cobra.Command{
 	Long: `Cette commande permet de récupérer et d'afficher le nombre total de clics
 pour une URL courte spécifique en utilisant son code.
 
-Exemple:
-  url-shortener stats --code="xyz123"`,
+Avec --from, --to ou --by, la commande affiche l'évolution des clics dans le temps,
+sous forme de tableau ou de sparkline (--format=sparkline).
+Avec --referrers, elle affiche les sites qui ont généré le plus de clics.
+
+Exemples:
+  url-shortener stats --code="xyz123"
+  url-shortener stats --code="xyz123" --from="2025-01-01" --to="2025-02-01" --by=day
+  url-shortener stats --code="xyz123" --by=hour --format=sparkline
+  url-shortener stats --code="xyz123" --referrers --top=5`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Valider que le flag --code a été fourni.
 		if shortCodeFlag == "" {
@@ -57,10 +95,11 @@ Exemple:
 
 		// Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		linkService := services.NewLinkService(linkRepo, nil)
+		clickService := services.NewClickService(repository.NewClickRepository(db))
 
 		// Appeler GetLinkStats pour récupérer le lien et ses statistiques.
-		link, totalClicks, err := linkService.GetLinkStats(shortCodeFlag)
+		link, totalClicks, err := linkService.GetLinkStats(shortCodeFlag, statsIncludeBotsFlag)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				log.Fatalf("FATAL: Lien non trouvé pour le code: %s", shortCodeFlag)
@@ -71,15 +110,120 @@ Exemple:
 		fmt.Printf("Statistiques pour le code court: %s\n", link.ShortCode)
 		fmt.Printf("URL longue: %s\n", link.LongURL)
 		fmt.Printf("Total de clics: %d\n", totalClicks)
+
+		// Mode série temporelle ou référents : uniquement si une période, une granularité
+		// ou le classement des référents est demandé.
+		if statsFromFlag == "" && statsToFlag == "" && statsByFlag == "" && !statsReferrersFlag {
+			return
+		}
+
+		filter := repository.ClickFilter{LinkID: link.ID, IncludeBots: statsIncludeBotsFlag}
+		if statsFromFlag != "" {
+			if filter.From, err = services.ParseDateTime(statsFromFlag); err != nil {
+				log.Fatalf("FATAL: Flag --from invalide: %v", err)
+			}
+		}
+		if statsToFlag != "" {
+			if filter.To, err = services.ParseDateTime(statsToFlag); err != nil {
+				log.Fatalf("FATAL: Flag --to invalide: %v", err)
+			}
+		}
+
+		if statsReferrersFlag {
+			referrers, err := clickService.GetTopReferrers(filter, statsTopFlag)
+			if err != nil {
+				log.Fatalf("FATAL: Erreur lors du classement des référents: %v", err)
+			}
+			printReferrersTable(referrers)
+			return
+		}
+
+		series, err := clickService.GetClickTimeSeries(filter, statsByFlag)
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors du calcul de la série temporelle: %v", err)
+		}
+
+		fmt.Printf("\nClics par %s du %s au %s (%d sur la période):\n",
+			granularityLabels[series.Granularity], series.From.Format(time.RFC3339), series.To.Format(time.RFC3339), series.Total)
+		switch statsFormatFlag {
+		case "sparkline":
+			fmt.Println(sparkline(series.Points))
+		case "table":
+			printTimeSeriesTable(series)
+		default:
+			log.Fatalf("FATAL: Format inconnu '%s' (attendu: table ou sparkline)", statsFormatFlag)
+		}
 	},
 }
 
+// printTimeSeriesTable affiche une série temporelle sous forme de tableau aligné.
+func printTimeSeriesTable(series *services.TimeSeries) {
+	layout := "2006-01-02"
+	if series.Granularity == repository.GranularityHour {
+		layout = "2006-01-02 15:04"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DÉBUT\tCLICS")
+	for _, point := range series.Points {
+		fmt.Fprintf(w, "%s\t%d\n", point.Start.Format(layout), point.Count)
+	}
+	w.Flush()
+}
+
+// printReferrersTable affiche le classement des sites référents sous forme de tableau aligné.
+func printReferrersTable(referrers *services.Breakdown) {
+	fmt.Printf("\nTop %d des référents (%d clics sur la période):\n", len(referrers.Entries), referrers.Total)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RÉFÉRENT\tCLICS\t%")
+	for _, entry := range referrers.Entries {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\n", entry.Value, entry.Count, entry.Percent)
+	}
+	w.Flush()
+}
+
+// sparkline dessine une série de points sur une ligne, chaque caractère représentant une tranche.
+// La hauteur est proportionnelle au maximum de la série.
+func sparkline(points []services.TimeSeriesPoint) string {
+	var max int64
+	for _, point := range points {
+		if point.Count > max {
+			max = point.Count
+		}
+	}
+
+	var sb strings.Builder
+	for _, point := range points {
+		level := 0
+		if max > 0 {
+			level = int(point.Count * int64(len(sparkLevels)-1) / max)
+		}
+		sb.WriteRune(sparkLevels[level])
+	}
+	sb.WriteString(fmt.Sprintf("  (max: %d)", max))
+	return sb.String()
+}
+
 // init() s'exécute automatiquement lors de l'importation du package.
 // Il est utilisé pour définir les flags que cette commande accepte.
 func init() {
 	// Définir le flag --code pour la commande stats.
 	StatsCmd.Flags().StringVar(&shortCodeFlag, "code", "", "Code court du lien (requis)")
 
+	// Définir le flag --include-bots (les clics de robots sont exclus par défaut).
+	StatsCmd.Flags().BoolVar(&statsIncludeBotsFlag, "include-bots", false, "Inclut les clics de robots (aperçus de liens, crawlers...) dans les statistiques")
+
+	// Définir les flags du mode série temporelle.
+	StatsCmd.Flags().StringVar(&statsFromFlag, "from", "", "Début de la période (YYYY-MM-DD ou RFC 3339)")
+	StatsCmd.Flags().StringVar(&statsToFlag, "to", "", "Fin de la période (YYYY-MM-DD ou RFC 3339, défaut: maintenant)")
+	StatsCmd.Flags().StringVar(&statsByFlag, "by", "", "Granularité de la série: hour, day ou week")
+	StatsCmd.Flags().StringVar(&statsFormatFlag, "format", "table", "Affichage de la série: table ou sparkline")
+
+	// Définir les flags du mode référents.
+	StatsCmd.Flags().BoolVar(&statsReferrersFlag, "referrers", false, "Affiche les sites référents ayant généré le plus de clics")
+	StatsCmd.Flags().IntVar(&statsTopFlag, "top", 10, "Nombre de référents affichés avec --referrers")
+
 	// Marquer le flag comme requis
 	StatsCmd.MarkFlagRequired("code")
 
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// thresholdsCodeFlag stocke la valeur du flag --code
+var thresholdsCodeFlag string
+
+// thresholdsFailureFlag et thresholdsRecoveryFlag stockent les valeurs des flags --failure et --recovery
+// (0 = valeur globale de la configuration)
+var thresholdsFailureFlag, thresholdsRecoveryFlag int
+
+// ThresholdsCmd représente la commande 'thresholds'
+var ThresholdsCmd = &cobra.Command{
+	Use:   "thresholds",
+	Short: "Définit les seuils de surveillance propres à un lien court.",
+	Long: `Cette commande définit, pour un lien, le nombre d'échecs consécutifs avant que le moniteur
+le déclare inaccessible (--failure) et le nombre de succès consécutifs avant de le déclarer
+de nouveau accessible (--recovery). Un seuil absent ou à 0 rétablit la valeur globale
+(monitor.failure_threshold / monitor.recovery_threshold).
+
+Exemples:
+  url-shortener thresholds --code="xyz123" --failure=5 --recovery=3
+  url-shortener thresholds --code="xyz123"   # Revenir aux seuils globaux`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Valider que le flag --code a été fourni.
+		if thresholdsCodeFlag == "" {
+			log.Fatalf("FATAL: Le flag --code est requis")
+		}
+		if thresholdsFailureFlag < 0 || thresholdsRecoveryFlag < 0 {
+			log.Fatalf("FATAL: Les seuils doivent être positifs (0 = valeur globale)")
+		}
+
+		// Charger la configuration chargée globalement via cmd.Cfg
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		// Initialiser la connexion à la base de données SQLite.
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+
+		// S'assurer que la connexion est fermée à la fin de l'exécution de la commande
+		defer func() {
+			if err := sqlDB.Close(); err != nil {
+				log.Printf("Erreur lors de la fermeture de la connexion: %v", err)
+			}
+		}()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo, nil)
+
+		link, err := linkService.SetHealthThresholds(thresholdsCodeFlag,
+			optionalThreshold(thresholdsFailureFlag), optionalThreshold(thresholdsRecoveryFlag))
+		if err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				log.Fatalf("FATAL: Lien non trouvé pour le code: %s", thresholdsCodeFlag)
+			}
+			var invalidQueryErr *customerrors.ErrInvalidQuery
+			if errors.As(err, &invalidQueryErr) {
+				log.Fatalf("FATAL: %v", invalidQueryErr)
+			}
+			log.Fatalf("FATAL: Erreur lors de la modification des seuils: %v", err)
+		}
+
+		fmt.Printf("Seuils de surveillance modifiés avec succès:\n")
+		fmt.Printf("Code: %s\n", link.ShortCode)
+		fmt.Printf("Échecs avant INACCESSIBLE: %s\n", formatThreshold(link.FailureThreshold, cfg.Monitor.FailureThreshold))
+		fmt.Printf("Succès avant ACCESSIBLE: %s\n", formatThreshold(link.RecoveryThreshold, cfg.Monitor.RecoveryThreshold))
+	},
+}
+
+// optionalThreshold convertit la valeur d'un flag en seuil optionnel (0 = valeur globale).
+func optionalThreshold(value int) *int {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+// formatThreshold affiche un seuil propre au lien, ou la valeur globale s'il n'est pas défini.
+func formatThreshold(value *int, global int) string {
+	if value == nil {
+		return fmt.Sprintf("%d (valeur globale)", global)
+	}
+	return fmt.Sprintf("%d", *value)
+}
+
+// init() s'exécute automatiquement lors de l'importation du package.
+// Il est utilisé pour définir les flags que cette commande accepte.
+func init() {
+	ThresholdsCmd.Flags().StringVar(&thresholdsCodeFlag, "code", "", "Code court du lien (requis)")
+	ThresholdsCmd.Flags().IntVar(&thresholdsFailureFlag, "failure", 0, "Échecs consécutifs avant de déclarer le lien inaccessible (0 = valeur globale)")
+	ThresholdsCmd.Flags().IntVar(&thresholdsRecoveryFlag, "recovery", 0, "Succès consécutifs avant de déclarer le lien accessible (0 = valeur globale)")
+
+	// Marquer les flags comme requis
+	ThresholdsCmd.MarkFlagRequired("code")
+
+	// Ajouter la commande à RootCmd
+	cmd2.RootCmd.AddCommand(ThresholdsCmd)
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// updateCodeFlag stocke la valeur du flag --code
+var updateCodeFlag string
+
+// updateURLFlag stocke la valeur du flag --url
+var updateURLFlag string
+
+// updateDownPolicyFlag et updateFallbackURLFlag stockent les valeurs des flags --down-policy et --fallback-url
+var updateDownPolicyFlag, updateFallbackURLFlag string
+
+// UpdateCmd représente la commande 'update'
+var UpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Modifie un lien court existant (destination, politique si la destination est inaccessible).",
+	Long: `Cette commande change l'URL longue vers laquelle redirige un code court, et/ou le comportement
+de la redirection lorsque le moniteur a confirmé que la destination est inaccessible :
+  redirect    : rediriger quand même vers l'URL longue (par défaut)
+  fallback    : rediriger vers l'URL de secours (--fallback-url)
+  unavailable : afficher une page "destination indisponible" (503)
+La redirection normale reprend d'elle-même dès que la destination est rétablie.
+Le code, la date de création et l'historique des clics sont conservés.
+
+Exemples:
+  url-shortener update --code="xyz123" --url="https://www.example.com/url-corrigee"
+  url-shortener update --code="xyz123" --down-policy=fallback --fallback-url="https://www.example.com/secours"
+  url-shortener update --code="xyz123" --down-policy=redirect --fallback-url=""`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Valider que le flag --code a été fourni, ainsi qu'au moins une modification.
+		if updateCodeFlag == "" {
+			log.Fatalf("FATAL: Le flag --code est requis")
+		}
+		var update services.LinkUpdate
+		if cmd.Flags().Changed("url") {
+			update.LongURL = &updateURLFlag
+		}
+		if cmd.Flags().Changed("down-policy") {
+			update.DownPolicy = &updateDownPolicyFlag
+		}
+		if cmd.Flags().Changed("fallback-url") {
+			update.FallbackURL = &updateFallbackURLFlag
+		}
+		if update.IsEmpty() {
+			log.Fatalf("FATAL: Au moins un des flags --url, --down-policy ou --fallback-url est requis")
+		}
+
+		// Charger la configuration chargée globalement via cmd.Cfg
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		// Initialiser la connexion à la base de données SQLite.
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+
+		// S'assurer que la connexion est fermée à la fin de l'exécution de la commande
+		defer func() {
+			if err := sqlDB.Close(); err != nil {
+				log.Printf("Erreur lors de la fermeture de la connexion: %v", err)
+			}
+		}()
+
+		// Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo, nil)
+
+		// Appeler le LinkService et la fonction UpdateLink pour modifier le lien.
+		link, err := linkService.UpdateLink(updateCodeFlag, update)
+		if err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				log.Fatalf("FATAL: Lien non trouvé pour le code: %s", updateCodeFlag)
+			}
+			var invalidURLErr *customerrors.ErrInvalidURL
+			if errors.As(err, &invalidURLErr) {
+				log.Fatalf("FATAL: %v", invalidURLErr)
+			}
+			var invalidPolicyErr *customerrors.ErrInvalidDownPolicy
+			if errors.As(err, &invalidPolicyErr) {
+				log.Fatalf("FATAL: %v", invalidPolicyErr)
+			}
+			log.Fatalf("FATAL: Erreur lors de la modification du lien: %v", err)
+		}
+
+		fmt.Printf("Lien modifié avec succès:\n")
+		fmt.Printf("Code: %s\n", link.ShortCode)
+		fmt.Printf("URL longue: %s\n", link.LongURL)
+		fmt.Printf("Si la destination est inaccessible: %s\n", link.DownPolicy)
+		if link.FallbackURL != "" {
+			fmt.Printf("URL de secours: %s\n", link.FallbackURL)
+		}
+	},
+}
+
+// init() s'exécute automatiquement lors de l'importation du package.
+// Il est utilisé pour définir les flags que cette commande accepte.
+func init() {
+	// Définir les flags de la commande update.
+	UpdateCmd.Flags().StringVar(&updateCodeFlag, "code", "", "Code court du lien à modifier (requis)")
+	UpdateCmd.Flags().StringVar(&updateURLFlag, "url", "", "Nouvelle URL longue de destination")
+	UpdateCmd.Flags().StringVar(&updateDownPolicyFlag, "down-policy", "", "Politique si la destination est inaccessible : redirect, fallback ou unavailable")
+	UpdateCmd.Flags().StringVar(&updateFallbackURLFlag, "fallback-url", "", "URL de secours de la politique fallback (\"\" pour la supprimer)")
+
+	// Marquer les flags comme requis
+	UpdateCmd.MarkFlagRequired("code")
+
+	// Ajouter la commande à RootCmd
+	cmd2.RootCmd.AddCommand(UpdateCmd)
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// noOwner est la valeur des flags --owner / --from désignant les liens sans propriétaire.
+const noOwner = "-"
+
+// userNameFlag stocke la valeur du flag --name de 'user create'
+var userNameFlag string
+
+// UserCmd représente la commande 'user', qui regroupe la gestion des utilisateurs
+var UserCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Gère les utilisateurs propriétaires de liens (create, list).",
+	Long: `Un utilisateur (ou une équipe) possède des liens. Une clé d'API rattachée à un utilisateur
+(apikey create --owner=...) ne voit, ne modifie et ne crée que les liens de cet utilisateur,
+sauf si elle a la permission admin.`,
+}
+
+// UserCreateCmd représente la commande 'user create'
+var UserCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Crée un utilisateur.",
+	Long: `Cette commande crée un utilisateur auquel rattacher des liens et des clés d'API.
+
+Exemple:
+  url-shortener user create --name="marketing"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if userNameFlag == "" {
+			log.Fatalf("FATAL: Le flag --name est requis")
+		}
+
+		db, closeDB := openDB()
+		defer closeDB()
+
+		userService := services.NewUserService(repository.NewUserRepository(db))
+		user, err := userService.CreateUser(userNameFlag)
+		if err != nil {
+			var userExistsErr *customerrors.ErrUserExists
+			if errors.As(err, &userExistsErr) {
+				log.Fatalf("FATAL: %v", userExistsErr)
+			}
+			var invalidQueryErr *customerrors.ErrInvalidQuery
+			if errors.As(err, &invalidQueryErr) {
+				log.Fatalf("FATAL: %v", invalidQueryErr)
+			}
+			log.Fatalf("FATAL: Erreur lors de la création de l'utilisateur: %v", err)
+		}
+
+		fmt.Printf("Utilisateur créé avec succès:\n")
+		fmt.Printf("ID: %d\n", user.ID)
+		fmt.Printf("Nom: %s\n", user.Name)
+	},
+}
+
+// UserListCmd représente la commande 'user list'
+var UserListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Affiche les utilisateurs.",
+	Run: func(cmd *cobra.Command, args []string) {
+		db, closeDB := openDB()
+		defer closeDB()
+
+		userService := services.NewUserService(repository.NewUserRepository(db))
+		users, err := userService.ListUsers()
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		if len(users) == 0 {
+			fmt.Println("Aucun utilisateur. Créez-en un avec: url-shortener user create --name=...")
+			return
+		}
+
+		fmt.Printf("Utilisateurs (%d):\n\n", len(users))
+		for _, user := range users {
+			fmt.Printf("%d. %s (créé le %s)\n", user.ID, user.Name, user.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+	},
+}
+
+// openDB ouvre la base de données configurée et retourne la connexion,
+// avec la fonction qui la ferme.
+func openDB() (*gorm.DB, func()) {
+	// Charger la configuration chargée globalement via cmd.Cfg
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		log.Fatalf("FATAL: Configuration non chargée")
+	}
+
+	// Initialiser la connexion à la base de données SQLite.
+	db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+	}
+
+	closeDB := func() {
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("Erreur lors de la fermeture de la connexion: %v", err)
+		}
+	}
+	return db, closeDB
+}
+
+// resolveOwner retourne l'ID de l'utilisateur nommé par un flag --owner ou --from,
+// ou nil pour noOwner (liens sans propriétaire). Termine la commande si l'utilisateur n'existe pas.
+func resolveOwner(db *gorm.DB, name string) *uint {
+	if name == noOwner {
+		return nil
+	}
+	userService := services.NewUserService(repository.NewUserRepository(db))
+	user, err := userService.GetUserByName(name)
+	if err != nil {
+		var notFoundErr *customerrors.ErrUserNotFound
+		if errors.As(err, &notFoundErr) {
+			log.Fatalf("FATAL: %v (voir 'url-shortener user list')", notFoundErr)
+		}
+		log.Fatalf("FATAL: %v", err)
+	}
+	return &user.ID
+}
+
+// listUserNames retourne le nom de chaque utilisateur, indexé par ID, pour l'affichage.
+func listUserNames(db *gorm.DB) map[uint]string {
+	userService := services.NewUserService(repository.NewUserRepository(db))
+	users, err := userService.ListUsers()
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	names := make(map[uint]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+	}
+	return names
+}
+
+func init() {
+	UserCreateCmd.Flags().StringVar(&userNameFlag, "name", "", "Nom de l'utilisateur ou de l'équipe (requis)")
+	UserCreateCmd.MarkFlagRequired("name")
+
+	// Ajouter les sous-commandes à 'user', puis 'user' à RootCmd
+	UserCmd.AddCommand(UserCreateCmd, UserListCmd)
+	cmd2.RootCmd.AddCommand(UserCmd)
+}
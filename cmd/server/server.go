@@ -11,10 +11,12 @@ import (
 	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/analytics"
 	"github.com/axellelanca/urlshortener/internal/api"
 	"github.com/axellelanca/urlshortener/internal/monitor"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/spill"
 	"github.com/axellelanca/urlshortener/internal/workers"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
@@ -46,13 +48,27 @@ puis lance le serveur HTTP.`,
 		// Initialiser les repositories.
 		linkRepo := repository.NewLinkRepository(db)
 		clickRepo := repository.NewClickRepository(db)
+		checkRepo := repository.NewLinkCheckRepository(db)
+		apiKeyRepo := repository.NewAPIKeyRepository(db)
 
 		// Laissez le log
 		log.Println("Repositories initialisés.")
 
 		// Initialiser les services métiers.
-		linkService := services.NewLinkService(linkRepo)
-		_ = services.NewClickService(clickRepo) // Service initialisé mais non utilisé directement ici
+		// Le générateur de codes courts suit la stratégie de la section 'shortcode' de la configuration.
+		codeGen, err := services.NewCodeGenerator(services.CodeGeneratorOptions{
+			Strategy: cfg.ShortCode.Strategy,
+			Length:   cfg.ShortCode.Length,
+			Alphabet: cfg.ShortCode.Alphabet,
+			Salt:     cfg.ShortCode.Salt,
+		}, linkRepo)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration des codes courts invalide: %v", err)
+		}
+		linkService := services.NewLinkService(linkRepo, codeGen)
+		clickService := services.NewClickService(clickRepo)
+		healthService := services.NewHealthService(checkRepo)
+		apiKeyService := services.NewAPIKeyService(apiKeyRepo)
 
 		// Laissez le log
 		log.Println("Services métiers initialisés.")
@@ -64,24 +80,130 @@ puis lance le serveur HTTP.`,
 
 		// Initialiser le channel dans SetupRoutes, mais on doit le créer avant
 		api.ClickEventsChannel = make(chan api.ClickEvent, cfg.Analytics.BufferSize)
-		workers.StartClickWorkers(ctx, cfg.Analytics.WorkerCount, api.ClickEventsChannel, clickRepo)
+
+		// Classifieur de robots : règles de User-Agent intégrées + plages d'IP configurées.
+		botClassifier, err := analytics.NewBotClassifier(cfg.Analytics.BotIPDenylist)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration des robots invalide: %v", err)
+		}
+
+		// Journal sur disque des clics qui ne tiennent plus en mémoire (channel plein, arrêt, échec d'écriture).
+		// Les clics laissés par l'exécution précédente sont écrits en base avant d'accepter du trafic.
+		spillQueue, err := spill.Open(cfg.Analytics.SpillDir)
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'ouverture du journal de clics: %v", err)
+		}
+		replayed, err := workers.ReplaySpill(spillQueue, clickRepo, botClassifier)
+		if err != nil {
+			log.Printf("Erreur lors du rejeu du journal de clics (les segments restants seront rejoués au prochain démarrage): %v", err)
+		}
+		if replayed > 0 {
+			log.Printf("%d clic(s) rejoué(s) depuis le journal %s.", replayed, cfg.Analytics.SpillDir)
+		}
+		api.ClickEventsSpill = spillQueue
+
+		clickWorkers := workers.StartClickWorkers(ctx, api.ClickEventsChannel, clickRepo, workers.ClickWorkerOptions{
+			WorkerCount:   cfg.Analytics.WorkerCount,
+			BatchSize:     cfg.Analytics.BatchSize,
+			FlushInterval: time.Duration(cfg.Analytics.FlushInterval) * time.Millisecond,
+			BotClassifier: botClassifier,
+			Spill:         spillQueue,
+		})
 
 		log.Printf("Channel d'événements de clic initialisé avec un buffer de %d. %d worker(s) de clics démarré(s).",
 			cfg.Analytics.BufferSize, cfg.Analytics.WorkerCount)
 
+		// Lancer le sweeper qui marque les liens arrivés à expiration.
+		if cfg.Links.ExpirySweepIntervalMinutes <= 0 {
+			log.Fatalf("FATAL: links.expiry_sweep_interval_minutes doit être positif")
+		}
+		sweepInterval := time.Duration(cfg.Links.ExpirySweepIntervalMinutes) * time.Minute
+		workers.StartExpirySweeper(ctx, linkRepo, sweepInterval)
+
 		// Initialiser et lancer le moniteur d'URLs.
 		// Utilisez l'intervalle configuré
 		monitorInterval := time.Duration(cfg.Monitor.IntervalMinutes) * time.Minute
-		urlMonitor := monitor.NewUrlMonitor(linkRepo, monitorInterval)
+		notifiers, err := monitor.NotifiersFromConfig(cfg.Monitor.Notifiers)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration des notifications invalide: %v", err)
+		}
+		healthyStatus, err := monitor.ParseStatusSet(cfg.Monitor.HealthyStatusCodes)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration monitor.healthy_status_codes invalide: %v", err)
+		}
+		urlMonitor := monitor.NewUrlMonitor(linkRepo, checkRepo, monitor.Options{
+			Interval:           monitorInterval,
+			Concurrency:        cfg.Monitor.Concurrency,
+			PerHostConcurrency: cfg.Monitor.PerHostConcurrency,
+			Jitter:             time.Duration(cfg.Monitor.JitterSeconds) * time.Second,
+			MaxRedirects:       cfg.Monitor.MaxRedirects,
+			HealthyStatus:      healthyStatus,
+			Notifiers:          notifiers,
+			NotifyRetry: monitor.RetryPolicy{
+				MaxAttempts:    cfg.Monitor.Notifiers.Retry.MaxAttempts,
+				InitialBackoff: time.Duration(cfg.Monitor.Notifiers.Retry.InitialBackoffMs) * time.Millisecond,
+				MaxBackoff:     time.Duration(cfg.Monitor.Notifiers.Retry.MaxBackoffMs) * time.Millisecond,
+			},
+			Thresholds: monitor.Thresholds{
+				Failure:    cfg.Monitor.FailureThreshold,
+				Recovery:   cfg.Monitor.RecoveryThreshold,
+				FlapWindow: time.Duration(cfg.Monitor.FlapWindowMinutes) * time.Minute,
+				FlapCount:  cfg.Monitor.FlapThreshold,
+			},
+			TLSExpiryWarning: time.Duration(cfg.Monitor.TLSExpiryWarningDays) * 24 * time.Hour,
+		})
 
-		// Lancez le moniteur dans sa propre goroutine.
-		go urlMonitor.Start()
+		// Lancez le moniteur dans sa propre goroutine. Il est arrêté par urlMonitor.Stop() à l'arrêt du serveur.
+		go urlMonitor.Start(ctx)
 
 		log.Printf("Moniteur d'URLs démarré avec un intervalle de %v.", monitorInterval)
 
 		// Configurer le routeur Gin et les handlers API.
 		router := gin.Default()
-		api.SetupRoutes(router, linkService, cfg.Analytics.BufferSize)
+		// Sans proxy de confiance, c.ClientIP() ignore X-Forwarded-For : un client ne peut pas choisir
+		// l'IP prise en compte par la limitation de débit et la détection des robots.
+		if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			log.Fatalf("FATAL: Configuration server.trusted_proxies invalide: %v", err)
+		}
+
+		// Limitation de débit par IP sur la création de liens (désactivable via la config).
+		var limiter *api.RateLimiter
+		if cfg.RateLimit.Enabled {
+			// Sans remplissage, un seau entamé ne redeviendrait jamais plein ni évincé : la mémoire ne serait plus bornée.
+			if cfg.RateLimit.RequestsPerMinute <= 0 {
+				log.Fatalf("FATAL: ratelimit.requests_per_minute doit être positif (ou désactivez ratelimit.enabled)")
+			}
+			// Sans délai d'inactivité, aucun seau ne serait évincé et la table des IP grossirait sans limite.
+			if cfg.RateLimit.IdleTimeoutMinutes <= 0 {
+				log.Fatalf("FATAL: ratelimit.idle_timeout_minutes doit être positif (ou désactivez ratelimit.enabled)")
+			}
+			limiter = api.NewRateLimiter(cfg.RateLimit.RequestsPerMinute, cfg.RateLimit.Burst,
+				time.Duration(cfg.RateLimit.IdleTimeoutMinutes)*time.Minute)
+			log.Printf("Limitation de débit activée : %d req/min par IP (rafale de %d).",
+				cfg.RateLimit.RequestsPerMinute, cfg.RateLimit.Burst)
+		}
+		// Page affichée pour les liens dont la destination est inaccessible (politique "unavailable").
+		if cfg.Links.UnavailablePage != "" {
+			if err := api.LoadUnavailablePage(cfg.Links.UnavailablePage); err != nil {
+				log.Fatalf("FATAL: Configuration links.unavailable_page invalide: %v", err)
+			}
+		}
+		// Déduplication des créations qui ne précisent pas "dedupe".
+		api.DedupeByDefault = cfg.Links.DedupeDefault
+		// Authentification par clé d'API des routes /api/v1 (désactivable via la config).
+		var auth api.APIKeyAuthenticator
+		if cfg.Auth.Enabled {
+			auth = apiKeyService
+			log.Println("Authentification par clé d'API activée sur /api/v1.")
+			warnIfNoActiveAPIKey(apiKeyService)
+		} else {
+			log.Println("**************************************************************************")
+			log.Println("ATTENTION : auth.enabled=false, l'API /api/v1 est accessible SANS CLÉ.")
+			log.Println("N'importe qui peut créer, modifier et supprimer des liens et lire les statistiques.")
+			log.Println("Ne désactivez l'authentification qu'en développement local.")
+			log.Println("**************************************************************************")
+		}
+		api.SetupRoutes(router, linkService, clickService, healthService, cfg.Analytics.BufferSize, limiter, auth)
 
 		// Pas toucher au log
 		log.Println("Routes API configurées.")
@@ -110,22 +232,101 @@ puis lance le serveur HTTP.`,
 		<-quit
 		log.Println("Signal d'arrêt reçu. Arrêt du serveur...")
 
-		// Arrêt propre du serveur HTTP avec un timeout.
-		log.Println("Arrêt en cours... Donnez un peu de temps aux workers pour finir.")
-		cancel() // Annuler le contexte pour arrêter les workers
-		time.Sleep(5 * time.Second)
-
-		// Fermer le serveur HTTP
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		// Arrêt ordonné, borné par un délai global : chaque étape ne démarre qu'une fois
+		// la précédente terminée, pour qu'aucun clic ne soit émis vers un pipeline déjà arrêté.
+		shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeoutSeconds) * time.Second
+		if shutdownTimeout <= 0 {
+			shutdownTimeout = 30 * time.Second
+		}
+		log.Printf("Arrêt en cours (délai maximum %v)...", shutdownTimeout)
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer shutdownCancel()
+
+		// 1. Ne plus accepter de requêtes HTTP et attendre la fin des requêtes en cours.
 		if err := srv.Shutdown(shutdownCtx); err != nil {
 			log.Printf("Erreur lors de l'arrêt du serveur: %v", err)
 		}
 
+		// 2. Fermer le channel de clics : les workers le vident puis s'arrêtent.
+		api.CloseClickEvents()
+
+		// 3. Attendre les workers. Passé le délai, on les arrête après leur lot en cours.
+		if err := clickWorkers.Wait(shutdownCtx); err != nil {
+			log.Printf("Les workers de clics n'ont pas fini dans le délai imparti (%v), arrêt forcé.", err)
+			cancel()
+			graceCtx, graceCancel := context.WithTimeout(context.Background(), workerStopGrace)
+			if err := clickWorkers.Wait(graceCtx); err != nil {
+				log.Printf("Les workers de clics ne se sont pas arrêtés : %v", err)
+			}
+			graceCancel()
+		}
+
+		// 4. Les clics restés dans le channel sont écrits dans le journal, pour être rejoués au prochain démarrage.
+		drainLost := spillPendingClicks(spillQueue)
+		if err := spillQueue.Close(); err != nil {
+			log.Printf("Erreur lors de la fermeture du journal de clics: %v", err)
+		}
+
+		// 5. Arrêter les processus de fond : le moniteur (en attendant la fin des vérifications en cours)
+		// et le sweeper d'expiration.
+		urlMonitor.Stop()
+		cancel()
+
+		// 6. Fermer la base de données.
+		if sqlDB, err := db.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				log.Printf("Erreur lors de la fermeture de la base de données: %v", err)
+			}
+		}
+
+		stats := clickWorkers.Stats()
+		lost := stats.Lost + api.DroppedClickEvents() + drainLost
+		log.Printf("Bilan des clics : %d écrit(s) en base, %d écrit(s) dans le journal, %d perdu(s).",
+			stats.Persisted, stats.Spilled, lost)
+
 		log.Println("Serveur arrêté proprement.")
 	},
 }
 
+// workerStopGrace est le temps laissé aux workers pour écrire leur lot en cours
+// lorsqu'ils sont arrêtés de force à l'expiration du délai d'arrêt.
+const workerStopGrace = 2 * time.Second
+
+// spillPendingClicks vide ClickEventsChannel dans le journal sur disque.
+// À appeler une fois le channel fermé (api.CloseClickEvents). Retourne le nombre de clics perdus.
+func spillPendingClicks(queue *spill.Queue) int64 {
+	var pending []api.ClickEvent
+	for ev := range api.ClickEventsChannel {
+		pending = append(pending, ev)
+	}
+	if len(pending) == 0 {
+		return 0
+	}
+	if err := queue.Append(pending...); err != nil {
+		log.Printf("Erreur: %d clic(s) en attente perdu(s), écriture du journal impossible: %v", len(pending), err)
+		return int64(len(pending))
+	}
+	log.Printf("%d clic(s) en attente écrit(s) dans le journal.", len(pending))
+	return 0
+}
+
+// warnIfNoActiveAPIKey signale au démarrage qu'aucune clé d'API n'est utilisable : avec
+// l'authentification activée, toutes les requêtes /api/v1 seraient alors refusées.
+func warnIfNoActiveAPIKey(apiKeyService *services.APIKeyService) {
+	keys, err := apiKeyService.ListAPIKeys()
+	if err != nil {
+		log.Printf("Warning: could not list API keys: %v", err)
+		return
+	}
+	for _, key := range keys {
+		if !key.IsRevoked() {
+			return
+		}
+	}
+	log.Println("ATTENTION : aucune clé d'API active, toutes les requêtes /api/v1 seront refusées (401).")
+	log.Println("Créez-en une avec : ./url-shortener apikey create --name=\"admin\" --scopes=\"admin\"")
+}
+
 func init() {
 	// Ajouter la commande
 	cmd2.RootCmd.AddCommand(RunServerCmd)
@@ -0,0 +1,85 @@
+package analytics
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MonitorUserAgent est le User-Agent envoyé par le moniteur d'URLs.
+// Il est reconnu comme un robot, au cas où le moniteur vérifierait un lien court de ce service.
+const MonitorUserAgent = "urlshortener-monitor/1.0"
+
+// botMarkers sont les marqueurs (en minuscules) des robots, crawlers, aperçus de liens
+// des messageries et réseaux sociaux, sondes de disponibilité et clients HTTP en ligne de commande.
+var botMarkers = []string{
+	// Marqueurs génériques
+	"bot", "crawler", "spider", "slurp", "crawl", "preview", "headless",
+	// Aperçus de liens (messageries, réseaux sociaux)
+	"facebookexternalhit", "facebookcatalog", "whatsapp", "skypeuripreview", "embedly",
+	"pinterest", "vkshare", "w3c_validator", "outbrain", "quora link preview",
+	// Sondes de disponibilité
+	"monitor", "uptime", "pingdom", "statuscake", "site24x7", "newrelicpinger", "datadog",
+	// Clients HTTP et bibliothèques
+	"curl/", "wget/", "go-http-client", "python-requests", "python-urllib", "aiohttp",
+	"okhttp", "java/", "libwww-perl", "httpclient", "axios/", "node-fetch",
+	// Notre propre moniteur d'URLs
+	strings.ToLower(MonitorUserAgent),
+}
+
+// isBotUserAgent indique si un User-Agent (déjà en minuscules) correspond à un robot connu.
+func isBotUserAgent(ua string) bool {
+	return containsAny(ua, botMarkers)
+}
+
+// BotClassifier détermine si un clic provient d'un robot, à partir du User-Agent
+// (règles intégrées) et d'une liste configurable de plages d'adresses IP (CIDR).
+type BotClassifier struct {
+	denylist []*net.IPNet // Plages d'IP dont tous les clics sont considérés comme des robots
+}
+
+// NewBotClassifier crée un BotClassifier à partir d'une liste de plages CIDR
+// (ex: "10.0.0.0/8", "2001:db8::/32"). Une adresse seule (ex: "203.0.113.7") est aussi acceptée.
+func NewBotClassifier(cidrs []string) (*BotClassifier, error) {
+	classifier := &BotClassifier{}
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("plage d'IP invalide '%s' dans la liste des robots : %w", cidr, err)
+		}
+		classifier.denylist = append(classifier.denylist, ipNet)
+	}
+	return classifier, nil
+}
+
+// IsBot indique si un clic provient d'un robot. Un classifieur nil n'applique que les règles de User-Agent.
+func (c *BotClassifier) IsBot(userAgent, ip string) bool {
+	if userAgent == "" || isBotUserAgent(strings.ToLower(userAgent)) {
+		return true
+	}
+	if c == nil || len(c.denylist) == 0 {
+		return false
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range c.denylist {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package analytics
+
+import "testing"
+
+// Quelques User-Agent de navigateurs réels, qui ne doivent jamais être pris pour des robots.
+const (
+	chromeUA  = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	firefoxUA = "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0"
+	safariUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Mobile/15E148 Safari/604.1"
+	edgeUA    = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.80"
+)
+
+func TestBotClassifierUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want bool
+	}{
+		{"Chrome", chromeUA, false},
+		{"Firefox", firefoxUA, false},
+		{"Safari iOS", safariUA, false},
+		{"Edge", edgeUA, false},
+		{"Googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"Bingbot", "Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)", true},
+		{"aperçu Slack", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", true},
+		{"aperçu Facebook", "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"aperçu WhatsApp", "WhatsApp/2.23.20.0", true},
+		{"Chrome headless", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/124.0.0.0 Safari/537.36", true},
+		{"sonde UptimeRobot", "Mozilla/5.0+(compatible; UptimeRobot/2.0; http://www.uptimerobot.com/)", true},
+		{"curl", "curl/8.5.0", true},
+		{"Wget", "Wget/1.21.4", true},
+		{"Python requests", "python-requests/2.31.0", true},
+		{"client HTTP Go", "Go-http-client/1.1", true},
+		{"moniteur d'URLs", MonitorUserAgent, true},
+		{"User-Agent vide", "", true},
+	}
+
+	var classifier *BotClassifier // Un classifieur nil n'applique que les règles de User-Agent
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifier.IsBot(tt.ua, "203.0.113.7"); got != tt.want {
+				t.Errorf("IsBot(%q) = %v, attendu %v", tt.ua, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBotClassifierDenylist(t *testing.T) {
+	classifier, err := NewBotClassifier([]string{"10.0.0.0/8", " 203.0.113.7 ", "2001:db8::/32", "2001:db8:ffff::1", ""})
+	if err != nil {
+		t.Fatalf("NewBotClassifier: %v", err)
+	}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"11.0.0.1", false},
+		{"203.0.113.7", true}, // Adresse seule : /32
+		{"203.0.113.8", false},
+		{"2001:db8:1::5", true},
+		{"2001:db9::1", false},
+		{"", false},
+		{"pas une IP", false},
+	}
+	for _, tt := range tests {
+		if got := classifier.IsBot(chromeUA, tt.ip); got != tt.want {
+			t.Errorf("IsBot(Chrome, %q) = %v, attendu %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestNewBotClassifierRejectsInvalidRange(t *testing.T) {
+	for _, cidr := range []string{"10.0.0.0/33", "pas-une-plage", "300.0.0.1"} {
+		if _, err := NewBotClassifier([]string{cidr}); err == nil {
+			t.Errorf("NewBotClassifier(%q) : plage invalide acceptée", cidr)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package analytics
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NormalizeReferrer réduit un en-tête Referer à l'hôte du site d'origine, en minuscules,
+// sans port ni préfixe "www." (ex: "https://www.Google.fr/search?q=x" devient "google.fr").
+// Une valeur vide ou illisible donne une chaîne vide, qui correspond à un accès direct.
+func NormalizeReferrer(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+	if referrer == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(referrer)
+	if err != nil || parsed.Hostname() == "" {
+		// Certains clients envoient l'hôte seul, sans schéma : on retente avec un schéma fictif.
+		parsed, err = url.Parse("http://" + referrer)
+		if err != nil || parsed.Hostname() == "" {
+			return ""
+		}
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	return strings.TrimSuffix(host, ".")
+}
@@ -0,0 +1,129 @@
+package analytics
+
+import "strings"
+
+// Classes d'appareil reconnues par ParseUserAgent.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// Unknown est la valeur utilisée lorsqu'aucune règle ne reconnaît le navigateur ou l'OS.
+const Unknown = "Other"
+
+// UserAgentInfo est le résultat de l'analyse d'un User-Agent.
+type UserAgentInfo struct {
+	Browser string // Famille de navigateur (ex: "Chrome", "Firefox")
+	OS      string // Famille de système d'exploitation (ex: "Windows", "iOS")
+	Device  string // Classe d'appareil : desktop, mobile, tablet ou bot
+}
+
+// uaRule associe une famille à une liste de marqueurs recherchés dans le User-Agent (en minuscules).
+// La règle s'applique si l'un des marqueurs est présent et qu'aucun marqueur d'exclusion ne l'est.
+type uaRule struct {
+	family   string
+	contains []string
+	excludes []string
+}
+
+// matches indique si la règle s'applique au User-Agent (déjà en minuscules).
+func (r uaRule) matches(ua string) bool {
+	for _, ex := range r.excludes {
+		if strings.Contains(ua, ex) {
+			return false
+		}
+	}
+	for _, c := range r.contains {
+		if strings.Contains(ua, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// browserRules est évaluée dans l'ordre : la première règle qui correspond l'emporte.
+// L'ordre compte car la plupart des navigateurs se déclarent aussi "Safari" ou "Chrome"
+// (ex: Edge contient "Chrome" et "Safari", Chrome contient "Safari").
+var browserRules = []uaRule{
+	{family: "Edge", contains: []string{"edg/", "edge/", "edga/", "edgios/"}},
+	{family: "Opera", contains: []string{"opr/", "opera"}},
+	{family: "Samsung Internet", contains: []string{"samsungbrowser"}},
+	{family: "Yandex", contains: []string{"yabrowser"}},
+	{family: "Firefox", contains: []string{"firefox/", "fxios/"}},
+	{family: "Chrome", contains: []string{"chrome/", "crios/", "chromium/"}},
+	{family: "Safari", contains: []string{"safari/"}, excludes: []string{"android"}},
+	{family: "Android WebView", contains: []string{"; wv)", "android"}},
+	{family: "Internet Explorer", contains: []string{"msie ", "trident/"}},
+	{family: "curl", contains: []string{"curl/"}},
+	{family: "Wget", contains: []string{"wget/"}},
+	{family: "Go HTTP Client", contains: []string{"go-http-client"}},
+	{family: "Python", contains: []string{"python-requests", "python-urllib", "aiohttp"}},
+}
+
+// osRules est évaluée dans l'ordre : la première règle qui correspond l'emporte.
+// iOS et Android sont testés avant macOS et Linux, dont ils reprennent les marqueurs.
+var osRules = []uaRule{
+	{family: "Windows Phone", contains: []string{"windows phone"}},
+	{family: "iOS", contains: []string{"iphone", "ipad", "ipod"}},
+	{family: "Android", contains: []string{"android"}},
+	{family: "Chrome OS", contains: []string{"cros "}},
+	{family: "Windows", contains: []string{"windows"}},
+	{family: "macOS", contains: []string{"mac os x", "macintosh"}},
+	{family: "Linux", contains: []string{"linux", "x11"}},
+}
+
+// tabletMarkers et mobileMarkers déterminent la classe d'appareil des navigateurs "humains".
+var (
+	tabletMarkers = []string{"ipad", "tablet", "kindle", "silk/", "playbook"}
+	mobileMarkers = []string{"mobi", "iphone", "ipod", "windows phone", "opera mini"}
+)
+
+// ParseUserAgent analyse un User-Agent avec un jeu de règles intégré (aucun accès réseau,
+// aucune base externe). Le résultat est volontairement grossier : famille de navigateur,
+// famille d'OS et classe d'appareil, suffisant pour des répartitions statistiques.
+func ParseUserAgent(userAgent string) UserAgentInfo {
+	ua := strings.ToLower(userAgent)
+	info := UserAgentInfo{Browser: Unknown, OS: Unknown, Device: DeviceDesktop}
+
+	if ua == "" {
+		// Un vrai navigateur envoie toujours un User-Agent : une requête sans en-tête est un script.
+		info.Device = DeviceBot
+		return info
+	}
+
+	for _, rule := range browserRules {
+		if rule.matches(ua) {
+			info.Browser = rule.family
+			break
+		}
+	}
+	for _, rule := range osRules {
+		if rule.matches(ua) {
+			info.OS = rule.family
+			break
+		}
+	}
+
+	switch {
+	case isBotUserAgent(ua):
+		info.Device = DeviceBot
+	case containsAny(ua, tabletMarkers) || (strings.Contains(ua, "android") && !strings.Contains(ua, "mobi")):
+		// Les tablettes Android n'ont pas le marqueur "Mobile" dans leur User-Agent.
+		info.Device = DeviceTablet
+	case containsAny(ua, mobileMarkers):
+		info.Device = DeviceMobile
+	}
+	return info
+}
+
+// containsAny indique si s contient au moins l'une des sous-chaînes.
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
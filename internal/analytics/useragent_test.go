@@ -0,0 +1,130 @@
+package analytics
+
+import "testing"
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want UserAgentInfo
+	}{
+		{
+			name: "Chrome Windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want: UserAgentInfo{Browser: "Chrome", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name: "Chrome macOS",
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want: UserAgentInfo{Browser: "Chrome", OS: "macOS", Device: DeviceDesktop},
+		},
+		{
+			name: "Chrome Android",
+			ua:   "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+			want: UserAgentInfo{Browser: "Chrome", OS: "Android", Device: DeviceMobile},
+		},
+		{
+			name: "Chrome iOS",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1",
+			want: UserAgentInfo{Browser: "Chrome", OS: "iOS", Device: DeviceMobile},
+		},
+		{
+			name: "Chrome OS",
+			ua:   "Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want: UserAgentInfo{Browser: "Chrome", OS: "Chrome OS", Device: DeviceDesktop},
+		},
+		{
+			name: "Firefox Windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			want: UserAgentInfo{Browser: "Firefox", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name: "Firefox Linux",
+			ua:   "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			want: UserAgentInfo{Browser: "Firefox", OS: "Linux", Device: DeviceDesktop},
+		},
+		{
+			name: "Firefox iOS",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) FxiOS/125.0 Mobile/15E148 Safari/605.1.15",
+			want: UserAgentInfo{Browser: "Firefox", OS: "iOS", Device: DeviceMobile},
+		},
+		{
+			name: "Safari iPhone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Mobile/15E148 Safari/604.1",
+			want: UserAgentInfo{Browser: "Safari", OS: "iOS", Device: DeviceMobile},
+		},
+		{
+			name: "Safari iPad",
+			ua:   "Mozilla/5.0 (iPad; CPU OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Mobile/15E148 Safari/604.1",
+			want: UserAgentInfo{Browser: "Safari", OS: "iOS", Device: DeviceTablet},
+		},
+		{
+			name: "Safari macOS",
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Safari/605.1.15",
+			want: UserAgentInfo{Browser: "Safari", OS: "macOS", Device: DeviceDesktop},
+		},
+		{
+			name: "Edge Windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.80",
+			want: UserAgentInfo{Browser: "Edge", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name: "Edge Android",
+			ua:   "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36 EdgA/124.0.2478.64",
+			want: UserAgentInfo{Browser: "Edge", OS: "Android", Device: DeviceMobile},
+		},
+		{
+			name: "Opera",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 OPR/109.0.0.0",
+			want: UserAgentInfo{Browser: "Opera", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name: "Samsung Internet tablette",
+			ua:   "Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Safari/537.36",
+			want: UserAgentInfo{Browser: "Samsung Internet", OS: "Android", Device: DeviceTablet},
+		},
+		{
+			name: "Android WebView",
+			ua:   "Mozilla/5.0 (Linux; Android 13; Pixel 7 Build/TQ3A.230805.001; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Mobile Safari/537.36",
+			want: UserAgentInfo{Browser: "Android WebView", OS: "Android", Device: DeviceMobile},
+		},
+		{
+			name: "Internet Explorer 11",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			want: UserAgentInfo{Browser: "Internet Explorer", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name: "Googlebot",
+			ua:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: UserAgentInfo{Browser: Unknown, OS: Unknown, Device: DeviceBot},
+		},
+		{
+			name: "Googlebot smartphone",
+			ua:   "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.118 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: UserAgentInfo{Browser: "Chrome", OS: "Android", Device: DeviceBot},
+		},
+		{
+			name: "curl",
+			ua:   "curl/8.5.0",
+			want: UserAgentInfo{Browser: "curl", OS: Unknown, Device: DeviceBot},
+		},
+		{
+			name: "User-Agent vide",
+			ua:   "",
+			want: UserAgentInfo{Browser: Unknown, OS: Unknown, Device: DeviceBot},
+		},
+		{
+			name: "User-Agent inconnu",
+			ua:   "SomeApp/1.0",
+			want: UserAgentInfo{Browser: Unknown, OS: Unknown, Device: DeviceDesktop},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseUserAgent(tt.ua); got != tt.want {
+				t.Errorf("ParseUserAgent(%q) = %+v, attendu %+v", tt.ua, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyContextKey est la clé sous laquelle la clé d'API authentifiée est rangée dans le contexte Gin.
+const apiKeyContextKey = "apiKey"
+
+// APIKeyAuthenticator vérifie la clé d'API présentée par un client.
+// Elle est satisfaite par services.APIKeyService.
+type APIKeyAuthenticator interface {
+	Authenticate(plain string) (*models.APIKey, error)
+}
+
+// RequireScope retourne un middleware qui exige une clé d'API valide accordant la permission scope,
+// transmise dans l'en-tête "Authorization: Bearer <clé>". Répond 401 sans clé valide et 403 si la
+// clé n'a pas la permission. Si auth est nil (authentification désactivée), toutes les requêtes passent.
+func RequireScope(auth APIKeyAuthenticator, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if auth == nil {
+			c.Next()
+			return
+		}
+
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Header("WWW-Authenticate", `Bearer realm="urlshortener"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing API key, expected an \"Authorization: Bearer <key>\" header"})
+			return
+		}
+
+		key, err := auth.Authenticate(token)
+		if err != nil {
+			var invalidKeyErr *customerrors.ErrInvalidAPIKey
+			if errors.As(err, &invalidKeyErr) {
+				c.Header("WWW-Authenticate", `Bearer realm="urlshortener", error="invalid_token"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or revoked API key"})
+				return
+			}
+			log.Printf("Error authenticating API key: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		if !key.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the required scope: " + scope})
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// CurrentAPIKey retourne la clé d'API authentifiée par RequireScope, ou nil si
+// l'authentification est désactivée.
+func CurrentAPIKey(c *gin.Context) *models.APIKey {
+	value, exists := c.Get(apiKeyContextKey)
+	if !exists {
+		return nil
+	}
+	key, _ := value.(*models.APIKey)
+	return key
+}
+
+// ownerScope retourne les liens visibles par la clé d'API de la requête : ceux de son utilisateur,
+// ou tous les liens pour une clé admin et lorsque l'authentification est désactivée.
+func ownerScope(c *gin.Context) repository.OwnerScope {
+	key := CurrentAPIKey(c)
+	if key == nil || key.HasScope(models.ScopeAdmin) {
+		return repository.OwnerScope{}
+	}
+	return repository.OwnedBy(key.UserID)
+}
+
+// RequireLinkAccess retourne un middleware, placé après RequireScope sur les routes /links/:shortCode,
+// qui répond 404 lorsque le lien appartient à un autre utilisateur que celui de la clé d'API.
+// On répond comme pour un lien inexistant pour ne pas révéler les codes courts des autres équipes.
+// Les autres cas (lien introuvable, erreur) sont laissés au handler.
+func RequireLinkAccess(linkService LinkServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := ownerScope(c)
+		if !scope.Restricted {
+			c.Next()
+			return
+		}
+
+		shortCode := c.Param("shortCode")
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err == nil && !scope.Allows(link) {
+			notFoundErr := &customerrors.ErrLinkNotFound{ShortCode: shortCode}
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// bearerToken extrait le jeton d'un en-tête Authorization de la forme "Bearer <jeton>".
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
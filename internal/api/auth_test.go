@@ -0,0 +1,250 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// authFixture regroupe les services réels branchés sur une base SQLite temporaire.
+type authFixture struct {
+	keys  *services.APIKeyService
+	links *services.LinkService
+	teamA *models.User
+	teamB *models.User
+}
+
+// newAuthFixture crée deux équipes, un lien pour chacune (linka, linkb) et un lien sans propriétaire (orphan).
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("ouverture de la base: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.AutoMigrate(&models.Link{}, &models.APIKey{}, &models.User{}); err != nil {
+		t.Fatalf("migration: %v", err)
+	}
+
+	f := &authFixture{
+		keys:  services.NewAPIKeyService(repository.NewAPIKeyRepository(db)),
+		links: services.NewLinkService(repository.NewLinkRepository(db), nil),
+		teamA: &models.User{Name: "team-a"},
+		teamB: &models.User{Name: "team-b"},
+	}
+	for _, user := range []*models.User{f.teamA, f.teamB} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("création de l'utilisateur: %v", err)
+		}
+	}
+	for _, link := range []*models.Link{
+		{ShortCode: "linka", LongURL: "https://a.example.com", OwnerID: &f.teamA.ID},
+		{ShortCode: "linkb", LongURL: "https://b.example.com", OwnerID: &f.teamB.ID},
+		{ShortCode: "orphan", LongURL: "https://orphan.example.com"},
+	} {
+		if err := db.Create(link).Error; err != nil {
+			t.Fatalf("création du lien: %v", err)
+		}
+	}
+	return f
+}
+
+// newKey crée une clé d'API et retourne sa valeur.
+func (f *authFixture) newKey(t *testing.T, scopes []string, userID *uint) string {
+	t.Helper()
+	_, plain, err := f.keys.CreateAPIKey("test", scopes, userID)
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	return plain
+}
+
+// newAuthRouter crée un routeur dont la route GET /links/:shortCode exige scope puis l'accès au lien.
+// auth nil désactive l'authentification, comme SetupRoutes.
+func newAuthRouter(auth APIKeyAuthenticator, linkService LinkServiceInterface, scope string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/links/:shortCode", RequireScope(auth, scope), RequireLinkAccess(linkService), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+// getLink envoie GET /links/shortCode avec l'en-tête Authorization donné (aucun s'il est vide).
+func getLink(router *gin.Engine, shortCode, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/links/"+shortCode, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireScopeRejectsMissingOrMalformedToken(t *testing.T) {
+	f := newAuthFixture(t)
+	router := newAuthRouter(f.keys, f.links, models.ScopeLinksRead)
+	key := f.newKey(t, []string{models.ScopeLinksRead}, nil)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantError     string // Attribut error attendu dans WWW-Authenticate (vide = aucun)
+	}{
+		{"sans en-tête", "", ""},
+		{"schéma Basic", "Basic dXNlcjpwYXNz", ""},
+		{"Bearer sans jeton", "Bearer ", ""},
+		{"clé sans schéma", key, ""},
+		{"clé inconnue", "Bearer " + services.APIKeyPrefix + "0000", `error="invalid_token"`},
+		{"jeton sans préfixe de clé", "Bearer abcdef", `error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := getLink(router, "orphan", tt.authorization)
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("HTTP %d, attendu 401", w.Code)
+			}
+			challenge := w.Header().Get("WWW-Authenticate")
+			if !strings.HasPrefix(challenge, "Bearer ") {
+				t.Errorf("WWW-Authenticate = %q", challenge)
+			}
+			if hasError := strings.Contains(challenge, "error="); hasError != (tt.wantError != "") ||
+				(tt.wantError != "" && !strings.Contains(challenge, tt.wantError)) {
+				t.Errorf("WWW-Authenticate = %q, attendu l'attribut %q", challenge, tt.wantError)
+			}
+		})
+	}
+
+	// Le schéma est insensible à la casse.
+	if w := getLink(router, "orphan", "bearer "+key); w.Code != http.StatusOK {
+		t.Errorf("schéma en minuscules : HTTP %d, attendu 200", w.Code)
+	}
+}
+
+func TestRequireScopeRejectsRevokedKey(t *testing.T) {
+	f := newAuthFixture(t)
+	router := newAuthRouter(f.keys, f.links, models.ScopeLinksRead)
+
+	key, plain, err := f.keys.CreateAPIKey("ci", []string{models.ScopeLinksRead}, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	if w := getLink(router, "orphan", "Bearer "+plain); w.Code != http.StatusOK {
+		t.Fatalf("avant révocation : HTTP %d, attendu 200", w.Code)
+	}
+
+	if err := f.keys.RevokeAPIKey(key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	w := getLink(router, "orphan", "Bearer "+plain)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("après révocation : HTTP %d, attendu 401", w.Code)
+	}
+	if challenge := w.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, `error="invalid_token"`) {
+		t.Errorf("WWW-Authenticate = %q", challenge)
+	}
+}
+
+func TestRequireScopeChecksScope(t *testing.T) {
+	f := newAuthFixture(t)
+	router := newAuthRouter(f.keys, f.links, models.ScopeLinksWrite)
+
+	tests := []struct {
+		scopes []string
+		want   int
+	}{
+		{[]string{models.ScopeLinksRead}, http.StatusForbidden},
+		{[]string{models.ScopeStatsRead, models.ScopeLinksRead}, http.StatusForbidden},
+		{[]string{models.ScopeLinksWrite}, http.StatusOK},
+		{[]string{models.ScopeAdmin}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		key := f.newKey(t, tt.scopes, nil)
+		if w := getLink(router, "orphan", "Bearer "+key); w.Code != tt.want {
+			t.Errorf("permissions %v : HTTP %d, attendu %d", tt.scopes, w.Code, tt.want)
+		}
+	}
+}
+
+// failingAuthenticator simule une base indisponible.
+type failingAuthenticator struct{}
+
+func (failingAuthenticator) Authenticate(string) (*models.APIKey, error) {
+	return nil, errors.New("base indisponible")
+}
+
+func TestRequireScopeAuthenticatorError(t *testing.T) {
+	f := newAuthFixture(t)
+	router := newAuthRouter(failingAuthenticator{}, f.links, models.ScopeLinksRead)
+
+	if w := getLink(router, "orphan", "Bearer "+services.APIKeyPrefix+"0000"); w.Code != http.StatusInternalServerError {
+		t.Errorf("HTTP %d, attendu 500", w.Code)
+	}
+}
+
+func TestRequireLinkAccessByOwner(t *testing.T) {
+	f := newAuthFixture(t)
+	router := newAuthRouter(f.keys, f.links, models.ScopeLinksRead)
+
+	keyA := f.newKey(t, []string{models.ScopeLinksRead}, &f.teamA.ID)
+	unowned := f.newKey(t, []string{models.ScopeLinksRead}, nil)
+	admin := f.newKey(t, []string{models.ScopeAdmin}, &f.teamA.ID)
+
+	tests := []struct {
+		name      string
+		key       string
+		shortCode string
+		want      int
+	}{
+		{"lien de son équipe", keyA, "linka", http.StatusOK},
+		{"lien d'une autre équipe", keyA, "linkb", http.StatusNotFound},
+		{"lien sans propriétaire", keyA, "orphan", http.StatusNotFound},
+		{"clé sans propriétaire, lien sans propriétaire", unowned, "orphan", http.StatusOK},
+		{"clé sans propriétaire, lien d'une équipe", unowned, "linka", http.StatusNotFound},
+		{"clé admin, lien d'une autre équipe", admin, "linkb", http.StatusOK},
+		// Lien inexistant : laissé au handler, qui répond lui-même 404.
+		{"lien inexistant", keyA, "missing", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := getLink(router, tt.shortCode, "Bearer "+tt.key)
+			if w.Code != tt.want {
+				t.Fatalf("HTTP %d, attendu %d", w.Code, tt.want)
+			}
+			// Un lien d'une autre équipe est indiscernable d'un lien inexistant.
+			if w.Code == http.StatusNotFound && !strings.Contains(w.Body.String(), tt.shortCode) {
+				t.Errorf("corps = %s, attendu l'erreur de lien introuvable", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthDisabledPassesThrough(t *testing.T) {
+	f := newAuthFixture(t)
+	router := newAuthRouter(nil, f.links, models.ScopeAdmin)
+
+	for _, authorization := range []string{"", "Bearer n-importe-quoi"} {
+		for _, shortCode := range []string{"linka", "linkb", "orphan"} {
+			if w := getLink(router, shortCode, authorization); w.Code != http.StatusOK {
+				t.Errorf("%s avec %q : HTTP %d, attendu 200", shortCode, authorization, w.Code)
+			}
+		}
+	}
+}
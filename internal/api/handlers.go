@@ -4,10 +4,15 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/axellelanca/urlshortener/internal/customerrors"
 	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,26 +25,106 @@ type ClickEvent struct {
 	Timestamp time.Time
 	UserAgent string
 	IP        string
+	Referrer  string // En-tête Referer brut, normalisé par les workers
 }
 
+// maxClickHeaderBytes borne la taille des en-têtes User-Agent et Referer copiés dans un
+// ClickEvent : un client ne peut pas ainsi gonfler la mémoire des workers ni produire
+// des lignes de spill démesurées.
+const maxClickHeaderBytes = 1024
+
 // ClickEventsChannel est le channel bufferisé global utilisé pour envoyer les événements
 // de clic aux workers asynchrones. Il est initialisé dans SetupRoutes si nil.
 var ClickEventsChannel chan ClickEvent
 
+// ClickEventSpiller reçoit les événements de clic qui ne peuvent pas être traités en mémoire.
+// Elle est implémentée par spill.Queue, qui les écrit sur disque pour un rejeu ultérieur.
+type ClickEventSpiller interface {
+	Append(events ...ClickEvent) error
+}
+
+// ClickEventsSpill reçoit les événements lorsque ClickEventsChannel est plein ou fermé.
+// Si elle est nil, ces événements sont perdus.
+var ClickEventsSpill ClickEventSpiller
+
+var (
+	clickEventsMu      sync.RWMutex // Protège l'envoi dans ClickEventsChannel contre sa fermeture
+	clickEventsClosed  bool         // Vrai une fois ClickEventsChannel fermé par CloseClickEvents
+	droppedClickEvents atomic.Int64 // Événements perdus par les handlers (ni channel ni spill)
+)
+
+// CloseClickEvents ferme ClickEventsChannel pour signaler aux workers qu'ils peuvent s'arrêter
+// une fois le channel vidé. Les clics reçus ensuite sont confiés à ClickEventsSpill.
+// Peut être appelée plusieurs fois.
+func CloseClickEvents() {
+	clickEventsMu.Lock()
+	defer clickEventsMu.Unlock()
+
+	if clickEventsClosed || ClickEventsChannel == nil {
+		return
+	}
+	close(ClickEventsChannel)
+	clickEventsClosed = true
+}
+
+// DroppedClickEvents retourne le nombre d'événements de clic perdus par les handlers depuis le démarrage.
+func DroppedClickEvents() int64 {
+	return droppedClickEvents.Load()
+}
+
+// sendClickEvent envoie un événement aux workers sans jamais bloquer.
+// Si le channel est plein ou fermé, l'événement est confié à ClickEventsSpill.
+func sendClickEvent(clickEvent ClickEvent) {
+	clickEventsMu.RLock()
+	if !clickEventsClosed {
+		select {
+		case ClickEventsChannel <- clickEvent:
+			clickEventsMu.RUnlock()
+			return
+		default:
+		}
+	}
+	clickEventsMu.RUnlock()
+
+	spillClickEvent(clickEvent)
+}
+
 // LinkServiceInterface définit le contrat minimal attendu par les handlers.
 // Nous déclarons une interface locale pour rester découplés de l'implémentation
 // concrète fournie par la Personne 2 (services.LinkService). Si ce dernier
 // implémente ces méthodes, il satisfera automatiquement cette interface.
 type LinkServiceInterface interface {
-	CreateLink(longURL string) (*models.Link, error)
+	CreateLink(longURL string, opts services.CreateLinkOptions) (*models.Link, bool, error)
 	GetLinkByShortCode(shortCode string) (*models.Link, error)
-	GetLinkStats(shortCode string) (*models.Link, int, error)
+	GetLinkForRedirect(shortCode string) (*models.Link, error)
+	GetLinkStats(shortCode string, includeBots bool) (*models.Link, int, error)
+	ListLinks(opts repository.ListLinksOptions) (*repository.LinkPage, error)
+	UpdateLink(shortCode string, update services.LinkUpdate) (*models.Link, error)
+	SetHealthThresholds(shortCode string, failure, recovery *int) (*models.Link, error)
+	DeleteLink(shortCode string) error
+}
+
+// ClickServiceInterface définit le contrat attendu par les handlers de statistiques avancées.
+// Comme LinkServiceInterface, elle est satisfaite par services.ClickService.
+type ClickServiceInterface interface {
+	GetClickTimeSeries(filter repository.ClickFilter, granularity string) (*services.TimeSeries, error)
+	GetClickBreakdown(filter repository.ClickFilter, dimension string) (*services.Breakdown, error)
+	GetTopReferrers(filter repository.ClickFilter, limit int) (*services.Breakdown, error)
+}
+
+// HealthServiceInterface définit le contrat attendu par le handler d'historique de disponibilité.
+// Elle est satisfaite par services.HealthService.
+type HealthServiceInterface interface {
+	GetLinkHealth(filter repository.LinkCheckFilter) (*services.LinkHealth, error)
 }
 
 // SetupRoutes configure toutes les routes de l'API Gin et injecte les dépendances nécessaires.
 // bufferSize permet de configurer la taille du channel pour les événements de clic.
 // Si bufferSize <= 0, on utilise une valeur par défaut raisonnable.
-func SetupRoutes(router *gin.Engine, linkService LinkServiceInterface, bufferSize int) {
+// limiter protège la création de liens ; s'il est nil, aucune limitation n'est appliquée.
+// auth vérifie les clés d'API exigées par les routes /api/v1 (voir RequireScope) ; s'il est nil,
+// l'API est ouverte. La redirection et /health restent publiques dans tous les cas.
+func SetupRoutes(router *gin.Engine, linkService LinkServiceInterface, clickService ClickServiceInterface, healthService HealthServiceInterface, bufferSize int, limiter *RateLimiter, auth APIKeyAuthenticator) {
 	// Défaut si non fourni
 	if bufferSize <= 0 {
 		bufferSize = 100
@@ -53,11 +138,26 @@ func SetupRoutes(router *gin.Engine, linkService LinkServiceInterface, bufferSiz
 	// Route de Health Check
 	router.GET("/health", HealthCheckHandler)
 
-	// Routes API
+	// Routes API, chacune protégée par la permission (scope) qu'elle exige
+	// Les routes d'un lien sont ensuite limitées aux liens du propriétaire de la clé (voir RequireLinkAccess).
+	scope := func(scope string) gin.HandlerFunc { return RequireScope(auth, scope) }
+	access := RequireLinkAccess(linkService)
 	api := router.Group("/api/v1")
 	{
-		api.POST("/links", CreateShortLinkHandler(linkService))
-		api.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService))
+		createHandlers := []gin.HandlerFunc{scope(models.ScopeLinksWrite), CreateShortLinkHandler(linkService)}
+		if limiter != nil {
+			createHandlers = append([]gin.HandlerFunc{limiter.Middleware()}, createHandlers...)
+		}
+		api.POST("/links", createHandlers...)
+		api.GET("/links", scope(models.ScopeLinksRead), ListLinksHandler(linkService))
+		api.PATCH("/links/:shortCode", scope(models.ScopeLinksWrite), access, UpdateLinkHandler(linkService))
+		api.DELETE("/links/:shortCode", scope(models.ScopeLinksWrite), access, DeleteLinkHandler(linkService))
+		api.GET("/links/:shortCode/stats", scope(models.ScopeStatsRead), access, GetLinkStatsHandler(linkService))
+		api.GET("/links/:shortCode/stats/timeseries", scope(models.ScopeStatsRead), access, GetLinkTimeSeriesHandler(linkService, clickService))
+		api.GET("/links/:shortCode/stats/breakdown", scope(models.ScopeStatsRead), access, GetLinkBreakdownHandler(linkService, clickService))
+		api.GET("/links/:shortCode/stats/referrers", scope(models.ScopeStatsRead), access, GetLinkReferrersHandler(linkService, clickService))
+		api.GET("/links/:shortCode/health", scope(models.ScopeLinksRead), access, GetLinkHealthHandler(linkService, healthService))
+		api.PUT("/links/:shortCode/health/thresholds", scope(models.ScopeLinksWrite), access, SetHealthThresholdsHandler(linkService))
 	}
 
 	// Route de Redirection (au niveau racine pour les short codes)
@@ -69,12 +169,24 @@ func HealthCheckHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
+// DedupeByDefault est la valeur de "dedupe" pour les créations qui ne la précisent pas
+// (links.dedupe_default dans la configuration, appliquée au démarrage du serveur).
+var DedupeByDefault bool
+
 // CreateLinkRequest représente le corps de la requête JSON pour la création d'un lien.
+// Alias est optionnel : s'il est absent, un code court aléatoire est généré.
+// ExpiresAt (RFC 3339) et TTL (durée Go, ex: "72h") sont optionnels et mutuellement exclusifs.
+// Dedupe (optionnel, DedupeByDefault si absent) retourne le lien existant pour la même URL.
 type CreateLinkRequest struct {
-	LongURL string `json:"long_url" binding:"required,url"`
+	LongURL   string     `json:"long_url" binding:"required,url"`
+	Alias     string     `json:"alias"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	TTL       string     `json:"ttl"`
+	Dedupe    *bool      `json:"dedupe"`
 }
 
 // CreateShortLinkHandler gère la création d'une URL courte.
+// Répond 201 avec le lien créé, ou 200 avec le lien existant lorsque la déduplication en trouve un.
 func CreateShortLinkHandler(linkService LinkServiceInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateLinkRequest
@@ -83,35 +195,305 @@ func CreateShortLinkHandler(linkService LinkServiceInterface) gin.HandlerFunc {
 			return
 		}
 
-		link, err := linkService.CreateLink(req.LongURL)
+		opts := services.CreateLinkOptions{Alias: req.Alias, ExpiresAt: req.ExpiresAt, Dedupe: DedupeByDefault}
+		if req.Dedupe != nil {
+			opts.Dedupe = *req.Dedupe
+		}
+		if key := CurrentAPIKey(c); key != nil {
+			// Le lien appartient à l'utilisateur de la clé d'API
+			opts.OwnerID = key.UserID
+		}
+		if req.TTL != "" {
+			ttl, err := time.ParseDuration(req.TTL)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ttl, expected a duration such as \"72h\""})
+				return
+			}
+			opts.TTL = ttl
+		}
+
+		link, created, err := linkService.CreateLink(req.LongURL, opts)
 		if err != nil {
+			var invalidURLErr *customerrors.ErrInvalidURL
+			if errors.As(err, &invalidURLErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidURLErr.Error()})
+				return
+			}
+			var invalidExpirationErr *customerrors.ErrInvalidExpiration
+			if errors.As(err, &invalidExpirationErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidExpirationErr.Error()})
+				return
+			}
+			var invalidAliasErr *customerrors.ErrInvalidAlias
+			if errors.As(err, &invalidAliasErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidAliasErr.Error()})
+				return
+			}
+			var aliasTakenErr *customerrors.ErrAliasTaken
+			if errors.As(err, &aliasTakenErr) {
+				c.JSON(http.StatusConflict, gin.H{"error": aliasTakenErr.Error()})
+				return
+			}
+			var collisionErr *customerrors.ErrCodeCollision
+			if errors.As(err, &collisionErr) {
+				// Temporaire : une nouvelle tentative a toutes les chances d'aboutir
+				log.Printf("CreateLink gave up after short code collisions: %v", collisionErr)
+				c.Header("Retry-After", "1")
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "could not allocate a unique short code, please retry"})
+				return
+			}
 			log.Printf("CreateLink error: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create short link"})
 			return
 		}
 
-		// Note: on utilise localhost:8080 ici comme fallback; l'appelant (cmd/server)
-		// peut facilement construire l'URL complète en utilisant la config si disponible.
-		c.JSON(http.StatusCreated, gin.H{
-			"short_code":     link.ShortCode,
-			"long_url":       link.LongURL,
-			"full_short_url": "http://localhost:8080/" + link.ShortCode,
+		if !created {
+			c.JSON(http.StatusOK, linkResponse(link))
+			return
+		}
+		c.JSON(http.StatusCreated, linkResponse(link))
+	}
+}
+
+// linkResponse construit la représentation JSON d'un lien renvoyée par l'API.
+// Note: on utilise localhost:8080 ici comme fallback; l'appelant (cmd/server)
+// peut facilement construire l'URL complète en utilisant la config si disponible.
+func linkResponse(link *models.Link) gin.H {
+	return gin.H{
+		"short_code":      link.ShortCode,
+		"long_url":        link.LongURL,
+		"full_short_url":  "http://localhost:8080/" + link.ShortCode,
+		"created_at":      link.CreatedAt,
+		"expires_at":      link.ExpiresAt,
+		"expired":         link.IsExpired(time.Now()),
+		"health_status":   link.HealthStatus,
+		"last_checked_at": link.LastCheckedAt,
+		"down_policy":     link.DownPolicy,
+		"fallback_url":    link.FallbackURL,
+		"owner_id":        link.OwnerID,
+	}
+}
+
+// queryTime lit un paramètre de requête optionnel contenant une date (RFC 3339 ou YYYY-MM-DD).
+// Si la valeur est invalide, une réponse 400 est envoyée et ok vaut false.
+func queryTime(c *gin.Context, param string) (t *time.Time, ok bool) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, true
+	}
+	parsed, err := services.ParseDateTime(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ": " + err.Error()})
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// queryIncludeBots lit le paramètre optionnel 'include_bots' des endpoints de statistiques.
+// Par défaut (false), les clics de robots sont exclus. Si la valeur est invalide,
+// une réponse 400 est envoyée et ok vaut false.
+func queryIncludeBots(c *gin.Context) (includeBots bool, ok bool) {
+	value := c.Query("include_bots")
+	if value == "" {
+		return false, true
+	}
+	includeBots, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid include_bots, expected true or false"})
+		return false, false
+	}
+	return includeBots, true
+}
+
+// queryClickFilter lit les paramètres communs des endpoints de statistiques avancées :
+// 'from' et 'to' (bornes optionnelles de la période) et 'include_bots'.
+// Le LinkID du filtre reste à renseigner. Si une valeur est invalide, une réponse 400 est envoyée et ok vaut false.
+func queryClickFilter(c *gin.Context) (filter repository.ClickFilter, ok bool) {
+	from, ok := queryTime(c, "from")
+	if !ok {
+		return filter, false
+	}
+	to, ok := queryTime(c, "to")
+	if !ok {
+		return filter, false
+	}
+	if from != nil {
+		filter.From = *from
+	}
+	if to != nil {
+		filter.To = *to
+	}
+	filter.IncludeBots, ok = queryIncludeBots(c)
+	return filter, ok
+}
+
+// ListLinksHandler gère la liste paginée des liens.
+// Paramètres de requête (tous optionnels) :
+//   - limit : taille de la page (défaut 20, max 100)
+//   - cursor : curseur "next_cursor" renvoyé par la page précédente
+//   - sort : created_at (défaut) ou clicks ; order : desc (défaut) ou asc
+//   - q : sous-chaîne recherchée dans l'URL longue
+//   - created_after / created_before : dates RFC 3339 ou YYYY-MM-DD
+//   - owner_id : ID de l'utilisateur propriétaire (clé admin uniquement)
+//
+// Une clé d'API sans permission admin ne voit que les liens de son utilisateur.
+func ListLinksHandler(linkService LinkServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := repository.ListLinksOptions{
+			Cursor: c.Query("cursor"),
+			SortBy: c.Query("sort"),
+			Order:  c.Query("order"),
+			Search: c.Query("q"),
+			Owner:  ownerScope(c),
+		}
+
+		if ownerID := c.Query("owner_id"); ownerID != "" {
+			if opts.Owner.Restricted {
+				c.JSON(http.StatusForbidden, gin.H{"error": "the owner_id filter requires the admin scope"})
+				return
+			}
+			id, err := strconv.ParseUint(ownerID, 10, 64)
+			if err != nil || id == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid owner_id, expected a positive integer"})
+				return
+			}
+			owner := uint(id)
+			opts.Owner = repository.OwnedBy(&owner)
+		}
+
+		if limit := c.Query("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit, expected a positive integer"})
+				return
+			}
+			opts.Limit = n
+		}
+		var ok bool
+		if opts.CreatedAfter, ok = queryTime(c, "created_after"); !ok {
+			return
+		}
+		if opts.CreatedBefore, ok = queryTime(c, "created_before"); !ok {
+			return
+		}
+
+		page, err := linkService.ListLinks(opts)
+		if err != nil {
+			var invalidQueryErr *customerrors.ErrInvalidQuery
+			if errors.As(err, &invalidQueryErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidQueryErr.Error()})
+				return
+			}
+			log.Printf("ListLinks error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		links := make([]gin.H, 0, len(page.Items))
+		for i := range page.Items {
+			item := linkResponse(&page.Items[i].Link)
+			item["total_clicks"] = page.Items[i].ClickCount
+			links = append(links, item)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"links":       links,
+			"next_cursor": page.NextCursor,
 		})
 	}
 }
 
+// UpdateLinkRequest représente le corps de la requête JSON pour la modification d'un lien.
+// Tous les champs sont optionnels : seuls ceux fournis sont modifiés.
+// FallbackURL vide ("") supprime l'URL de secours.
+type UpdateLinkRequest struct {
+	LongURL     *string `json:"long_url" binding:"omitempty,url"`
+	DownPolicy  *string `json:"down_policy"`
+	FallbackURL *string `json:"fallback_url"`
+}
+
+// UpdateLinkHandler gère la modification d'un lien existant : URL de destination, et
+// comportement de la redirection lorsque le moniteur a confirmé que la destination est inaccessible.
+func UpdateLinkHandler(linkService LinkServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req UpdateLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		update := services.LinkUpdate{LongURL: req.LongURL, DownPolicy: req.DownPolicy, FallbackURL: req.FallbackURL}
+		if update.IsEmpty() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "nothing to update, expected long_url, down_policy or fallback_url"})
+			return
+		}
+
+		link, err := linkService.UpdateLink(shortCode, update)
+		if err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				c.JSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
+				return
+			}
+			var invalidURLErr *customerrors.ErrInvalidURL
+			if errors.As(err, &invalidURLErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidURLErr.Error()})
+				return
+			}
+			var invalidPolicyErr *customerrors.ErrInvalidDownPolicy
+			if errors.As(err, &invalidPolicyErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidPolicyErr.Error()})
+				return
+			}
+			log.Printf("UpdateLink error for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update link"})
+			return
+		}
+
+		c.JSON(http.StatusOK, linkResponse(link))
+	}
+}
+
+// DeleteLinkHandler gère la suppression (douce) d'un lien : il ne redirige plus,
+// mais son historique de clics est conservé.
+func DeleteLinkHandler(linkService LinkServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		if err := linkService.DeleteLink(shortCode); err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				c.JSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
+				return
+			}
+			log.Printf("DeleteLink error for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete link"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
 // RedirectHandler gère la redirection d'une URL courte vers l'URL longue et l'enregistrement asynchrone des clics.
 func RedirectHandler(linkService LinkServiceInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		shortCode := c.Param("shortCode")
 
-		link, err := linkService.GetLinkByShortCode(shortCode)
+		link, err := linkService.GetLinkForRedirect(shortCode)
 		if err != nil {
 			var notFoundErr *customerrors.ErrLinkNotFound
 			if errors.As(err, &notFoundErr) {
 				c.JSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
 				return
 			}
+			var expiredErr *customerrors.ErrLinkExpired
+			if errors.As(err, &expiredErr) {
+				c.JSON(http.StatusGone, gin.H{"error": expiredErr.Error()})
+				return
+			}
 			log.Printf("Error retrieving link for %s: %v", shortCode, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 			return
@@ -122,29 +504,53 @@ func RedirectHandler(linkService LinkServiceInterface) gin.HandlerFunc {
 			LinkID:    link.ID,
 			ShortCode: shortCode,
 			Timestamp: time.Now().UTC(),
-			UserAgent: c.GetHeader("User-Agent"),
+			UserAgent: models.Truncate(c.GetHeader("User-Agent"), maxClickHeaderBytes),
 			IP:        c.ClientIP(),
+			Referrer:  models.Truncate(c.GetHeader("Referer"), maxClickHeaderBytes),
 		}
 
 		// Envoi non-bloquant dans le channel pour ne jamais ralentir la redirection.
-		select {
-		case ClickEventsChannel <- clickEvent:
-			// envoyé avec succès
+		sendClickEvent(clickEvent)
+
+		// Si le moniteur a confirmé que la destination est inaccessible, appliquer la politique du lien.
+		switch link.ActiveDownPolicy() {
+		case models.DownPolicyFallback:
+			c.Redirect(http.StatusFound, link.FallbackURL)
+		case models.DownPolicyUnavailable:
+			renderUnavailablePage(c, link)
 		default:
-			log.Printf("Warning: ClickEventsChannel is full, dropping click event for %s.", shortCode)
+			// Redirection instantanée vers l'URL longue
+			c.Redirect(http.StatusFound, link.LongURL)
 		}
+	}
+}
 
-		// Redirection instantanée vers l'URL longue
-		c.Redirect(http.StatusFound, link.LongURL)
+// spillClickEvent écrit sur disque un événement qui ne tient plus dans ClickEventsChannel.
+// L'écriture est synchrone : elle ralentit la redirection, mais uniquement en cas de surcharge ou d'arrêt.
+func spillClickEvent(clickEvent ClickEvent) {
+	if ClickEventsSpill == nil {
+		droppedClickEvents.Add(1)
+		log.Printf("Warning: ClickEventsChannel is unavailable, dropping click event for %s.", clickEvent.ShortCode)
+		return
+	}
+	if err := ClickEventsSpill.Append(clickEvent); err != nil {
+		droppedClickEvents.Add(1)
+		log.Printf("Error: ClickEventsChannel is unavailable and spill failed, dropping click event for %s: %v", clickEvent.ShortCode, err)
 	}
 }
 
 // GetLinkStatsHandler gère la récupération des statistiques pour un lien spécifique.
+// Les clics de robots sont exclus, sauf avec le paramètre de requête include_bots=true.
 func GetLinkStatsHandler(linkService LinkServiceInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		shortCode := c.Param("shortCode")
 
-		link, totalClicks, err := linkService.GetLinkStats(shortCode)
+		includeBots, ok := queryIncludeBots(c)
+		if !ok {
+			return
+		}
+
+		link, totalClicks, err := linkService.GetLinkStats(shortCode, includeBots)
 		if err != nil {
 			var notFoundErr *customerrors.ErrLinkNotFound
 			if errors.As(err, &notFoundErr) {
@@ -163,3 +569,289 @@ func GetLinkStatsHandler(linkService LinkServiceInterface) gin.HandlerFunc {
 		})
 	}
 }
+
+// GetLinkTimeSeriesHandler gère la récupération des clics d'un lien regroupés par tranche de temps.
+// Paramètres de requête (tous optionnels) :
+//   - from / to : bornes de la période (RFC 3339 ou YYYY-MM-DD), 'to' par défaut à maintenant
+//   - granularity : hour, day (défaut) ou week
+//   - include_bots : true pour inclure les clics de robots (exclus par défaut)
+func GetLinkTimeSeriesHandler(linkService LinkServiceInterface, clickService ClickServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		filter, ok := queryClickFilter(c)
+		if !ok {
+			return
+		}
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				c.JSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
+				return
+			}
+			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		filter.LinkID = link.ID
+		series, err := clickService.GetClickTimeSeries(filter, c.Query("granularity"))
+		if err != nil {
+			var invalidQueryErr *customerrors.ErrInvalidQuery
+			if errors.As(err, &invalidQueryErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidQueryErr.Error()})
+				return
+			}
+			log.Printf("Error getting time series for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code":  link.ShortCode,
+			"granularity": series.Granularity,
+			"from":        series.From,
+			"to":          series.To,
+			"total":       series.Total,
+			"points":      series.Points,
+		})
+	}
+}
+
+// GetLinkBreakdownHandler gère la répartition des clics d'un lien selon une dimension.
+// Paramètres de requête :
+//   - dimension : browser, os ou device (requis)
+//   - from / to : bornes optionnelles de la période (RFC 3339 ou YYYY-MM-DD)
+//   - include_bots : true pour inclure les clics de robots (exclus par défaut)
+func GetLinkBreakdownHandler(linkService LinkServiceInterface, clickService ClickServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		filter, ok := queryClickFilter(c)
+		if !ok {
+			return
+		}
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				c.JSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
+				return
+			}
+			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		filter.LinkID = link.ID
+		breakdown, err := clickService.GetClickBreakdown(filter, c.Query("dimension"))
+		if err != nil {
+			var invalidQueryErr *customerrors.ErrInvalidQuery
+			if errors.As(err, &invalidQueryErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidQueryErr.Error()})
+				return
+			}
+			log.Printf("Error getting breakdown for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"dimension":  breakdown.Dimension,
+			"total":      breakdown.Total,
+			"entries":    breakdown.Entries,
+		})
+	}
+}
+
+// GetLinkReferrersHandler gère le classement des sites référents d'un lien.
+// Paramètres de requête (tous optionnels) :
+//   - limit : nombre de référents retournés (défaut 10, max 100)
+//   - from / to : bornes de la période (RFC 3339 ou YYYY-MM-DD)
+//   - include_bots : true pour inclure les clics de robots (exclus par défaut)
+func GetLinkReferrersHandler(linkService LinkServiceInterface, clickService ClickServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		filter, ok := queryClickFilter(c)
+		if !ok {
+			return
+		}
+		limit := 0
+		if value := c.Query("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 || n > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit, expected an integer between 1 and 100"})
+				return
+			}
+			limit = n
+		}
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				c.JSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
+				return
+			}
+			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		filter.LinkID = link.ID
+		referrers, err := clickService.GetTopReferrers(filter, limit)
+		if err != nil {
+			log.Printf("Error getting referrers for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"total":      referrers.Total,
+			"referrers":  referrers.Entries,
+		})
+	}
+}
+
+// GetLinkHealthHandler gère l'historique de disponibilité de la destination d'un lien,
+// tel qu'enregistré par le moniteur d'URLs.
+// Paramètres de requête (tous optionnels) :
+//   - limit : nombre de vérifications retournées, les plus récentes d'abord (défaut 50, max 500)
+//   - from / to : bornes de la période (RFC 3339 ou YYYY-MM-DD)
+func GetLinkHealthHandler(linkService LinkServiceInterface, healthService HealthServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var filter repository.LinkCheckFilter
+		from, ok := queryTime(c, "from")
+		if !ok {
+			return
+		}
+		to, ok := queryTime(c, "to")
+		if !ok {
+			return
+		}
+		if from != nil {
+			filter.From = *from
+		}
+		if to != nil {
+			filter.To = *to
+		}
+		if value := c.Query("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 || n > services.MaxHealthChecksLimit {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit, expected an integer between 1 and 500"})
+				return
+			}
+			filter.Limit = n
+		}
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				c.JSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
+				return
+			}
+			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		filter.LinkID = link.ID
+		health, err := healthService.GetLinkHealth(filter)
+		if err != nil {
+			log.Printf("Error getting health history for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code":      link.ShortCode,
+			"long_url":        link.LongURL,
+			"status":          link.HealthStatus,
+			"last_checked_at": link.LastCheckedAt,
+			"thresholds":      thresholdsResponse(link),
+			"tls":             tlsResponse(link),
+			"total_checks":    health.Total,
+			"failed_checks":   health.Failures,
+			"uptime_percent":  health.UptimePercent,
+			"checks":          health.Checks,
+		})
+	}
+}
+
+// HealthThresholdsRequest représente le corps de la requête JSON de modification des seuils du moniteur
+// pour un lien. Un seuil absent ou null rétablit la valeur globale de la configuration.
+type HealthThresholdsRequest struct {
+	FailureThreshold  *int `json:"failure_threshold"`
+	RecoveryThreshold *int `json:"recovery_threshold"`
+}
+
+// SetHealthThresholdsHandler gère la modification des seuils d'échecs et de succès consécutifs
+// à partir desquels le moniteur déclare la destination d'un lien inaccessible ou de nouveau accessible.
+func SetHealthThresholdsHandler(linkService LinkServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req HealthThresholdsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		link, err := linkService.SetHealthThresholds(shortCode, req.FailureThreshold, req.RecoveryThreshold)
+		if err != nil {
+			var notFoundErr *customerrors.ErrLinkNotFound
+			if errors.As(err, &notFoundErr) {
+				c.JSON(http.StatusNotFound, gin.H{"error": notFoundErr.Error()})
+				return
+			}
+			var invalidQueryErr *customerrors.ErrInvalidQuery
+			if errors.As(err, &invalidQueryErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": invalidQueryErr.Error()})
+				return
+			}
+			log.Printf("SetHealthThresholds error for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update thresholds"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"thresholds": thresholdsResponse(link),
+		})
+	}
+}
+
+// tlsResponse construit la représentation JSON du dernier certificat TLS examiné par le moniteur,
+// ou nil si aucun certificat n'a été examiné (URL http, destination jamais jointe).
+func tlsResponse(link *models.Link) gin.H {
+	if link.TLSStatus == "" {
+		return nil
+	}
+	response := gin.H{
+		"status":         link.TLSStatus,
+		"expires_at":     link.TLSExpiresAt,
+		"issuer":         link.TLSIssuer,
+		"hostname_valid": link.TLSHostnameValid,
+	}
+	if link.TLSExpiresAt != nil {
+		response["days_left"] = int(time.Until(*link.TLSExpiresAt).Hours() / 24)
+	}
+	return response
+}
+
+// thresholdsResponse construit la représentation JSON des seuils propres à un lien (null = valeur globale).
+func thresholdsResponse(link *models.Link) gin.H {
+	return gin.H{
+		"failure_threshold":  link.FailureThreshold,
+		"recovery_threshold": link.RecoveryThreshold,
+	}
+}
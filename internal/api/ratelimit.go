@@ -0,0 +1,137 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenBucket représente le seau de jetons d'un client (une adresse IP).
+// Le seau se remplit en continu au rythme 'rate' jusqu'à la capacité 'burst'.
+type tokenBucket struct {
+	tokens   float64   // Nombre de jetons disponibles (fractionnaire pendant le remplissage)
+	lastSeen time.Time // Dernière mise à jour du seau, sert au calcul du remplissage et à l'éviction
+}
+
+// RateLimiter implémente une limitation de débit "token bucket" par adresse IP.
+// Les seaux inactifs depuis plus de idleTTL (et donc pleins) sont supprimés
+// au fil de l'eau pour que la mémoire consommée reste bornée.
+type RateLimiter struct {
+	rate      float64                 // Jetons ajoutés par seconde
+	burst     int                     // Capacité maximale d'un seau
+	idleTTL   time.Duration           // Durée d'inactivité au-delà de laquelle un seau est évincé
+	buckets   map[string]*tokenBucket // Seaux indexés par IP
+	lastSweep time.Time               // Date du dernier passage d'éviction
+	mu        sync.Mutex              // Protège buckets et lastSweep
+	now       func() time.Time        // Horloge (remplacée dans les tests)
+}
+
+// NewRateLimiter crée un RateLimiter autorisant requestsPerMinute requêtes par minute et par IP,
+// avec des rafales jusqu'à burst requêtes. requestsPerMinute et idleTTL doivent être positifs
+// pour que les seaux inactifs soient évincés.
+func NewRateLimiter(requestsPerMinute, burst int, idleTTL time.Duration) *RateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:      float64(requestsPerMinute) / 60.0,
+		burst:     burst,
+		idleTTL:   idleTTL,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// rateLimitDecision décrit le résultat d'une demande de jeton.
+type rateLimitDecision struct {
+	allowed    bool          // true si la requête peut passer
+	remaining  int           // Jetons restants après la requête
+	retryAfter time.Duration // Délai avant qu'un jeton soit disponible (si refusée)
+	resetAfter time.Duration // Délai avant que le seau soit de nouveau plein
+}
+
+// allow consomme un jeton du seau associé à key si possible.
+func (l *RateLimiter) allow(key string) rateLimitDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.evictIdle(now)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(l.burst), lastSeen: now}
+		l.buckets[key] = bucket
+	} else {
+		bucket.tokens = l.refill(bucket, now)
+		bucket.lastSeen = now
+	}
+
+	decision := rateLimitDecision{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		decision.allowed = true
+	} else if l.rate > 0 {
+		decision.retryAfter = time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+	} else {
+		// Sans remplissage, le client ne récupérera jamais de jeton : on lui demande d'attendre idleTTL.
+		decision.retryAfter = l.idleTTL
+	}
+
+	decision.remaining = int(math.Floor(bucket.tokens))
+	if l.rate > 0 {
+		decision.resetAfter = time.Duration((float64(l.burst) - bucket.tokens) / l.rate * float64(time.Second))
+	}
+	return decision
+}
+
+// refill calcule le nombre de jetons d'un seau à l'instant now, plafonné à burst.
+func (l *RateLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	return math.Min(float64(l.burst), bucket.tokens+elapsed*l.rate)
+}
+
+// evictIdle supprime les seaux inactifs depuis plus de idleTTL et entièrement remplis.
+// Un seau plein est équivalent à un seau absent, l'éviction ne change donc pas les limites appliquées.
+// Doit être appelée avec l.mu verrouillé.
+func (l *RateLimiter) evictIdle(now time.Time) {
+	if l.idleTTL <= 0 || now.Sub(l.lastSweep) < l.idleTTL {
+		return
+	}
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= l.idleTTL && l.refill(bucket, now) >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Middleware retourne un middleware Gin qui applique la limitation par IP client (c.ClientIP()).
+// Il ajoute les en-têtes X-RateLimit-* à chaque réponse et répond 429 avec Retry-After
+// lorsque le client a épuisé ses jetons.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		decision := l.allow(c.ClientIP())
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(l.burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.resetAfter)))
+
+		if !decision.allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(decision.retryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded, retry later"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds arrondit une durée à la seconde supérieure (les en-têtes HTTP attendent des secondes entières).
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeClock est une horloge avancée à la main par les tests.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestRateLimiter crée un RateLimiter piloté par une fakeClock.
+func newTestRateLimiter(requestsPerMinute, burst int, idleTTL time.Duration) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l := NewRateLimiter(requestsPerMinute, burst, idleTTL)
+	l.now = clock.now
+	l.lastSweep = clock.t
+	return l, clock
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l, _ := newTestRateLimiter(60, 3, 10*time.Minute)
+
+	for i := 0; i < 3; i++ {
+		d := l.allow("1.2.3.4")
+		if !d.allowed {
+			t.Fatalf("requête %d refusée dans la rafale", i+1)
+		}
+		if d.remaining != 2-i {
+			t.Errorf("requête %d : remaining = %d, attendu %d", i+1, d.remaining, 2-i)
+		}
+	}
+	d := l.allow("1.2.3.4")
+	if d.allowed {
+		t.Fatal("requête au-delà de la rafale acceptée")
+	}
+	if d.retryAfter != time.Second {
+		t.Errorf("retryAfter = %v, attendu 1s (60 req/min)", d.retryAfter)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l, clock := newTestRateLimiter(60, 5, 10*time.Minute)
+
+	for i := 0; i < 5; i++ {
+		l.allow("1.2.3.4")
+	}
+	clock.advance(2500 * time.Millisecond) // 2,5 jetons à 1 jeton par seconde
+
+	for i := 0; i < 2; i++ {
+		if !l.allow("1.2.3.4").allowed {
+			t.Fatalf("requête %d refusée après remplissage", i+1)
+		}
+	}
+	d := l.allow("1.2.3.4")
+	if d.allowed {
+		t.Fatal("troisième requête acceptée avec un demi-jeton")
+	}
+	if d.retryAfter != 500*time.Millisecond {
+		t.Errorf("retryAfter = %v, attendu 500ms", d.retryAfter)
+	}
+
+	// Le seau ne dépasse jamais sa capacité.
+	clock.advance(time.Hour)
+	if d := l.allow("1.2.3.4"); d.remaining != 4 {
+		t.Errorf("remaining = %d après une longue pause, attendu 4 (burst - 1)", d.remaining)
+	}
+}
+
+func TestRateLimiterPerIPIsolation(t *testing.T) {
+	l, _ := newTestRateLimiter(60, 2, 10*time.Minute)
+
+	l.allow("1.1.1.1")
+	l.allow("1.1.1.1")
+	if l.allow("1.1.1.1").allowed {
+		t.Fatal("1.1.1.1 aurait dû être limité")
+	}
+	if !l.allow("2.2.2.2").allowed {
+		t.Error("2.2.2.2 limité par la consommation de 1.1.1.1")
+	}
+}
+
+func TestRateLimiterEvictsIdleFullBuckets(t *testing.T) {
+	// 1 req/min et une rafale de 30 : après 11 minutes, un seau vidé n'est pas encore plein.
+	l, clock := newTestRateLimiter(1, 30, 10*time.Minute)
+
+	l.allow("idle")
+	for i := 0; i < 30; i++ {
+		l.allow("drained")
+	}
+
+	clock.advance(11 * time.Minute)
+	l.allow("other") // Déclenche le passage d'éviction
+
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("seau inactif et plein non évincé")
+	}
+	if _, ok := l.buckets["drained"]; !ok {
+		t.Error("seau inactif mais pas encore plein évincé : sa limite serait perdue")
+	}
+
+	// Une fois plein, il est évincé à son tour.
+	clock.advance(30 * time.Minute)
+	l.allow("other")
+	if _, ok := l.buckets["drained"]; ok {
+		t.Error("seau redevenu plein non évincé")
+	}
+	if len(l.buckets) != 1 {
+		t.Errorf("%d seau(x) restant(s), attendu 1", len(l.buckets))
+	}
+}
+
+func TestRateLimiterDoesNotSweepBeforeIdleTTL(t *testing.T) {
+	l, clock := newTestRateLimiter(60, 5, 10*time.Minute)
+
+	l.allow("a")
+	clock.advance(9 * time.Minute)
+	l.allow("b")
+	if len(l.buckets) != 2 {
+		t.Errorf("%d seau(x), attendu 2 : aucun seau n'est inactif depuis idleTTL", len(l.buckets))
+	}
+}
+
+// newRateLimitedRouter crée un routeur protégé par l, qui fait confiance aux proxies trusted.
+func newRateLimitedRouter(t *testing.T, l *RateLimiter, trusted []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(trusted); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+	router.Use(l.Middleware())
+	router.POST("/shorten", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return router
+}
+
+// postShorten envoie une requête depuis remoteAddr, avec un en-tête X-Forwarded-For si xff n'est pas vide.
+func postShorten(router *gin.Engine, remoteAddr, xff string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/shorten", nil)
+	req.RemoteAddr = remoteAddr
+	if xff != "" {
+		req.Header.Set("X-Forwarded-For", xff)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiterMiddlewareHeaders(t *testing.T) {
+	l, _ := newTestRateLimiter(60, 1, 10*time.Minute)
+	router := newRateLimitedRouter(t, l, nil)
+
+	w := postShorten(router, "203.0.113.7:5000", "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("première requête : HTTP %d", w.Code)
+	}
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "1" {
+		t.Errorf("X-RateLimit-Limit = %q", got)
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q", got)
+	}
+
+	w = postShorten(router, "203.0.113.7:5000", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("deuxième requête : HTTP %d, attendu 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, attendu 1", got)
+	}
+}
+
+func TestRateLimiterIgnoresUntrustedForwardedFor(t *testing.T) {
+	l, _ := newTestRateLimiter(60, 2, 10*time.Minute)
+	router := newRateLimitedRouter(t, l, nil)
+
+	// Sans proxy de confiance, changer d'X-Forwarded-For ne donne pas de nouveau seau.
+	for i, xff := range []string{"198.51.100.1", "198.51.100.2"} {
+		if w := postShorten(router, "203.0.113.7:5000", xff); w.Code != http.StatusCreated {
+			t.Fatalf("requête %d : HTTP %d", i+1, w.Code)
+		}
+	}
+	if w := postShorten(router, "203.0.113.7:5000", "198.51.100.3"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("X-Forwarded-For falsifié accepté : HTTP %d, attendu 429", w.Code)
+	}
+}
+
+func TestRateLimiterUsesForwardedForFromTrustedProxy(t *testing.T) {
+	l, _ := newTestRateLimiter(60, 1, 10*time.Minute)
+	router := newRateLimitedRouter(t, l, []string{"10.0.0.1"})
+
+	// Derrière le proxy de confiance, chaque client a son propre seau.
+	for _, xff := range []string{"198.51.100.1", "198.51.100.2"} {
+		if w := postShorten(router, "10.0.0.1:4000", xff); w.Code != http.StatusCreated {
+			t.Errorf("client %s : HTTP %d, attendu 201", xff, w.Code)
+		}
+	}
+	if w := postShorten(router, "10.0.0.1:4000", "198.51.100.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("client 198.51.100.1 non limité : HTTP %d", w.Code)
+	}
+}
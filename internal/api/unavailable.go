@@ -0,0 +1,74 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// unavailableRetryAfter est la valeur (en secondes) de l'en-tête Retry-After de la page "destination indisponible".
+const unavailableRetryAfter = "300"
+
+// defaultUnavailablePage est la page affichée par défaut aux visiteurs d'un lien dont la destination
+// est inaccessible et dont la politique est models.DownPolicyUnavailable.
+const defaultUnavailablePage = `<!DOCTYPE html>
+<html lang="fr">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Destination indisponible</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f5f6f8; color: #222; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; }
+main { background: #fff; border-radius: 8px; padding: 2.5rem; max-width: 32rem; box-shadow: 0 2px 12px rgba(0, 0, 0, .08); text-align: center; }
+h1 { font-size: 1.4rem; margin-top: 0; }
+p { line-height: 1.5; color: #555; }
+</style>
+</head>
+<body>
+<main>
+<h1>Cette page est momentanément indisponible</h1>
+<p>Le site vers lequel pointe le lien <strong>{{.ShortCode}}</strong> ne répond pas pour le moment.</p>
+<p>Nous le surveillons : le lien fonctionnera de nouveau dès son rétablissement. Merci de réessayer un peu plus tard.</p>
+</main>
+</body>
+</html>
+`
+
+// UnavailablePage est le modèle de la page "destination indisponible" (code 503). Il reçoit un
+// unavailablePageData. Il peut être remplacé au démarrage par LoadUnavailablePage.
+var UnavailablePage = template.Must(template.New("unavailable").Parse(defaultUnavailablePage))
+
+// unavailablePageData est la donnée passée au modèle UnavailablePage.
+type unavailablePageData struct {
+	ShortCode string
+}
+
+// LoadUnavailablePage remplace la page "destination indisponible" par le modèle html/template
+// du fichier path (ex: une page aux couleurs de la marque). Le modèle peut utiliser {{.ShortCode}}.
+func LoadUnavailablePage(path string) error {
+	tmpl, err := template.ParseFiles(path)
+	if err != nil {
+		return fmt.Errorf("page d'indisponibilité invalide : %w", err)
+	}
+	UnavailablePage = tmpl
+	return nil
+}
+
+// renderUnavailablePage répond 503 avec la page "destination indisponible" du lien.
+func renderUnavailablePage(c *gin.Context, link *models.Link) {
+	var page bytes.Buffer
+	if err := UnavailablePage.Execute(&page, unavailablePageData{ShortCode: link.ShortCode}); err != nil {
+		log.Printf("Error rendering unavailable page for %s: %v", link.ShortCode, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "destination unavailable"})
+		return
+	}
+	// Pas de mise en cache : la redirection doit reprendre dès que la destination est rétablie.
+	c.Header("Cache-Control", "no-store")
+	c.Header("Retry-After", unavailableRetryAfter)
+	c.Data(http.StatusServiceUnavailable, "text/html; charset=utf-8", page.Bytes())
+}
@@ -22,12 +22,20 @@ type Config struct {
 	Database  DatabaseConfig  `mapstructure:"database"`  // Configuration de la base de données
 	Analytics AnalyticsConfig `mapstructure:"analytics"` // Configuration des analytics (workers)
 	Monitor   MonitorConfig   `mapstructure:"monitor"`   // Configuration du moniteur d'URLs
+	Links     LinksConfig     `mapstructure:"links"`     // Configuration du cycle de vie des liens
+	RateLimit RateLimitConfig `mapstructure:"ratelimit"` // Configuration de la limitation de débit
+	Auth      AuthConfig      `mapstructure:"auth"`      // Configuration de l'authentification par clé d'API
+	ShortCode ShortCodeConfig `mapstructure:"shortcode"` // Configuration de la génération des codes courts
 }
 
 // ServerConfig contient les paramètres du serveur HTTP Gin
 type ServerConfig struct {
 	Port    int    `mapstructure:"port"`     // Port d'écoute (ex: 8080)
 	BaseURL string `mapstructure:"base_url"` // URL de base pour construire les URLs courtes complètes
+
+	ShutdownTimeoutSeconds int `mapstructure:"shutdown_timeout_seconds"` // Délai maximum de l'arrêt propre
+
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // IP ou plages CIDR des reverse proxies dont l'en-tête X-Forwarded-For est cru
 }
 
 // DatabaseConfig contient les paramètres de la base de données
@@ -37,13 +45,106 @@ type DatabaseConfig struct {
 
 // AnalyticsConfig contient les paramètres pour le système d'analytics asynchrone
 type AnalyticsConfig struct {
-	BufferSize  int `mapstructure:"buffer_size"`  // Taille du buffer du channel de clics
-	WorkerCount int `mapstructure:"worker_count"` // Nombre de goroutines workers
+	BufferSize    int      `mapstructure:"buffer_size"`       // Taille du buffer du channel de clics
+	WorkerCount   int      `mapstructure:"worker_count"`      // Nombre de goroutines workers
+	BotIPDenylist []string `mapstructure:"bot_ip_denylist"`   // Plages CIDR dont les clics sont considérés comme des robots
+	BatchSize     int      `mapstructure:"batch_size"`        // Nombre maximum de clics écrits en une seule transaction
+	FlushInterval int      `mapstructure:"flush_interval_ms"` // Délai maximum (ms) avant l'écriture d'un lot incomplet
+	SpillDir      string   `mapstructure:"spill_dir"`         // Répertoire du journal des clics non traités en mémoire
 }
 
 // MonitorConfig contient les paramètres pour le moniteur d'URLs
 type MonitorConfig struct {
-	IntervalMinutes int `mapstructure:"interval_minutes"` // Intervalle de vérification en minutes
+	IntervalMinutes    int `mapstructure:"interval_minutes"`     // Intervalle de vérification en minutes
+	Concurrency        int `mapstructure:"concurrency"`          // Nombre maximum de vérifications simultanées
+	PerHostConcurrency int `mapstructure:"per_host_concurrency"` // Nombre maximum de vérifications simultanées vers un même hôte
+	JitterSeconds      int `mapstructure:"jitter_seconds"`       // Délai aléatoire maximum avant chaque vérification périodique
+	MaxRedirects       int `mapstructure:"max_redirects"`        // Nombre maximum de redirections suivies par vérification
+
+	HealthyStatusCodes []string `mapstructure:"healthy_status_codes"` // Codes ou plages ("200-399") de réponses considérées comme saines
+
+	FailureThreshold  int `mapstructure:"failure_threshold"`   // Échecs consécutifs avant de déclarer un lien inaccessible
+	RecoveryThreshold int `mapstructure:"recovery_threshold"`  // Succès consécutifs avant de déclarer un lien de nouveau accessible
+	FlapWindowMinutes int `mapstructure:"flap_window_minutes"` // Fenêtre d'observation des changements d'état
+	FlapThreshold     int `mapstructure:"flap_threshold"`      // Changements d'état dans la fenêtre à partir desquels les notifications sont suspendues
+
+	TLSExpiryWarningDays int `mapstructure:"tls_expiry_warning_days"` // Jours avant l'expiration d'un certificat à partir desquels on prévient
+
+	Notifiers NotifiersConfig `mapstructure:"notifiers"` // Canaux de notification des changements d'état
+}
+
+// NotifiersConfig contient les canaux de notification du moniteur (chacun activable séparément)
+type NotifiersConfig struct {
+	Retry   NotifierRetryConfig   `mapstructure:"retry"`   // Nouvelles tentatives en cas d'échec d'envoi
+	Webhook WebhookNotifierConfig `mapstructure:"webhook"` // Webhook JSON générique signé par HMAC
+	Slack   SlackNotifierConfig   `mapstructure:"slack"`   // Webhook entrant compatible Slack
+	Email   EmailNotifierConfig   `mapstructure:"email"`   // E-mail via SMTP
+	File    FileNotifierConfig    `mapstructure:"file"`    // Fichier JSON lines ou sortie standard
+}
+
+// NotifierRetryConfig contient la politique de nouvelles tentatives des notifications
+type NotifierRetryConfig struct {
+	MaxAttempts      int `mapstructure:"max_attempts"`       // Nombre total de tentatives par notification
+	InitialBackoffMs int `mapstructure:"initial_backoff_ms"` // Délai avant la deuxième tentative (doublé à chaque échec)
+	MaxBackoffMs     int `mapstructure:"max_backoff_ms"`     // Délai maximum entre deux tentatives
+}
+
+// WebhookNotifierConfig contient les paramètres du webhook générique
+type WebhookNotifierConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	URL     string `mapstructure:"url"`    // URL appelée en POST
+	Secret  string `mapstructure:"secret"` // Secret partagé pour la signature HMAC-SHA256 (optionnel)
+}
+
+// SlackNotifierConfig contient les paramètres du webhook entrant Slack
+type SlackNotifierConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	WebhookURL string `mapstructure:"webhook_url"`
+}
+
+// EmailNotifierConfig contient les paramètres d'envoi par e-mail
+type EmailNotifierConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// FileNotifierConfig contient les paramètres de l'écriture des notifications dans un fichier
+type FileNotifierConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"` // "-" pour la sortie standard
+}
+
+// LinksConfig contient les paramètres liés au cycle de vie des liens
+type LinksConfig struct {
+	ExpirySweepIntervalMinutes int    `mapstructure:"expiry_sweep_interval_minutes"` // Intervalle entre deux passages du sweeper d'expiration
+	UnavailablePage            string `mapstructure:"unavailable_page"`              // Modèle HTML de la page "destination indisponible" (vide = page par défaut)
+	DedupeDefault              bool   `mapstructure:"dedupe_default"`                // Retourner le lien existant pour une même URL si la requête ne précise pas "dedupe"
+}
+
+// RateLimitConfig contient les paramètres de la limitation de débit par IP sur la création de liens
+type RateLimitConfig struct {
+	Enabled            bool `mapstructure:"enabled"`              // Active ou non la limitation
+	RequestsPerMinute  int  `mapstructure:"requests_per_minute"`  // Débit soutenu autorisé par IP
+	Burst              int  `mapstructure:"burst"`                // Nombre de requêtes autorisées en rafale
+	IdleTimeoutMinutes int  `mapstructure:"idle_timeout_minutes"` // Inactivité après laquelle l'état d'une IP est oublié
+}
+
+// AuthConfig contient les paramètres de l'authentification des routes /api/v1 par clé d'API
+type AuthConfig struct {
+	Enabled bool `mapstructure:"enabled"` // Exige une clé d'API (en-tête "Authorization: Bearer <clé>") sur /api/v1
+}
+
+// ShortCodeConfig contient les paramètres de la génération des codes courts
+type ShortCodeConfig struct {
+	Strategy string `mapstructure:"strategy"` // random, sequential, hashids ou pronounceable
+	Length   int    `mapstructure:"length"`   // Longueur des codes (minimale pour sequential et hashids)
+	Alphabet string `mapstructure:"alphabet"` // Caractères de la stratégie random (vide = base62)
+	Salt     string `mapstructure:"salt"`     // Secret de brouillage de la stratégie hashids
 }
 
 // LoadConfig charge la configuration de l'application en utilisant Viper.
@@ -67,10 +168,42 @@ func LoadConfig() (*Config, error) {
 	// ou si le fichier n'existe pas. C'est une bonne pratique pour la robustesse.
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.base_url", "http://localhost:8080")
+	viper.SetDefault("server.shutdown_timeout_seconds", 30)
+	viper.SetDefault("server.trusted_proxies", []string{})
 	viper.SetDefault("database.name", "url_shortener.db")
 	viper.SetDefault("analytics.buffer_size", 1000)
 	viper.SetDefault("analytics.worker_count", 5)
+	viper.SetDefault("analytics.bot_ip_denylist", []string{})
+	viper.SetDefault("analytics.batch_size", 100)
+	viper.SetDefault("analytics.flush_interval_ms", 500)
+	viper.SetDefault("analytics.spill_dir", "spill")
 	viper.SetDefault("monitor.interval_minutes", 5)
+	viper.SetDefault("monitor.concurrency", 10)
+	viper.SetDefault("monitor.per_host_concurrency", 2)
+	viper.SetDefault("monitor.jitter_seconds", 30)
+	viper.SetDefault("monitor.max_redirects", 10)
+	viper.SetDefault("monitor.healthy_status_codes", []string{"200-399"})
+	viper.SetDefault("monitor.failure_threshold", 3)
+	viper.SetDefault("monitor.recovery_threshold", 2)
+	viper.SetDefault("monitor.flap_window_minutes", 60)
+	viper.SetDefault("monitor.flap_threshold", 4)
+	viper.SetDefault("monitor.tls_expiry_warning_days", 14)
+	viper.SetDefault("monitor.notifiers.retry.max_attempts", 3)
+	viper.SetDefault("monitor.notifiers.retry.initial_backoff_ms", 1000)
+	viper.SetDefault("monitor.notifiers.retry.max_backoff_ms", 30000)
+	viper.SetDefault("monitor.notifiers.email.port", 587)
+	viper.SetDefault("monitor.notifiers.file.path", "-")
+	viper.SetDefault("links.expiry_sweep_interval_minutes", 1)
+	viper.SetDefault("links.dedupe_default", false)
+	viper.SetDefault("ratelimit.enabled", true)
+	viper.SetDefault("ratelimit.requests_per_minute", 30)
+	viper.SetDefault("ratelimit.burst", 10)
+	viper.SetDefault("ratelimit.idle_timeout_minutes", 10)
+	viper.SetDefault("auth.enabled", true)
+	viper.SetDefault("shortcode.strategy", "random")
+	viper.SetDefault("shortcode.length", 6)
+	viper.SetDefault("shortcode.alphabet", "")
+	viper.SetDefault("shortcode.salt", "")
 
 	// Étape 5: Lire le fichier de configuration
 	// ReadInConfig() cherche et lit le fichier config.yaml
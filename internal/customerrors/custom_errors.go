@@ -25,6 +25,39 @@ func (e *ErrLinkNotFound) Error() string {
 	return fmt.Sprintf("le lien avec le code court '%s' est introuvable", e.ShortCode)
 }
 
+// ErrLinkExpired est retournée lorsqu'un lien existe mais que sa date d'expiration est dépassée.
+// Elle permet de répondre "410 Gone" plutôt que "404 Not Found".
+type ErrLinkExpired struct {
+	ShortCode string // Le code court du lien expiré
+}
+
+// Error implémente l'interface error pour ErrLinkExpired
+func (e *ErrLinkExpired) Error() string {
+	return fmt.Sprintf("le lien avec le code court '%s' a expiré", e.ShortCode)
+}
+
+// ErrInvalidExpiration est retournée lorsque la date d'expiration ou la durée de vie demandée est incohérente.
+type ErrInvalidExpiration struct {
+	Reason string // La raison du refus
+}
+
+// Error implémente l'interface error pour ErrInvalidExpiration
+func (e *ErrInvalidExpiration) Error() string {
+	return fmt.Sprintf("expiration invalide: %s", e.Reason)
+}
+
+// ErrInvalidQuery est retournée lorsqu'un paramètre de requête (pagination, tri, filtre...) est invalide.
+// Elle permet aux handlers HTTP de répondre avec un "400 Bad Request".
+type ErrInvalidQuery struct {
+	Param  string // Le nom du paramètre refusé
+	Reason string // La raison du refus
+}
+
+// Error implémente l'interface error pour ErrInvalidQuery
+func (e *ErrInvalidQuery) Error() string {
+	return fmt.Sprintf("paramètre '%s' invalide: %s", e.Param, e.Reason)
+}
+
 // ErrInvalidURL est retournée lorsqu'une URL fournie est invalide ou mal formée.
 type ErrInvalidURL struct {
 	URL    string // L'URL invalide
@@ -64,3 +97,87 @@ func (e *ErrDatabaseOperation) Error() string {
 func (e *ErrDatabaseOperation) Unwrap() error {
 	return e.Err
 }
+
+// ErrInvalidAlias est retournée lorsqu'un alias personnalisé ne respecte pas les règles
+// (jeu de caractères, longueur ou mot réservé).
+type ErrInvalidAlias struct {
+	Alias  string // L'alias refusé
+	Reason string // La raison du refus
+}
+
+// Error implémente l'interface error pour ErrInvalidAlias
+func (e *ErrInvalidAlias) Error() string {
+	return fmt.Sprintf("alias invalide '%s': %s", e.Alias, e.Reason)
+}
+
+// ErrAliasTaken est retournée lorsqu'un alias personnalisé est déjà utilisé par un autre lien.
+// Elle permet aux handlers HTTP de répondre avec un "409 Conflict".
+type ErrAliasTaken struct {
+	Alias string // L'alias déjà pris
+}
+
+// Error implémente l'interface error pour ErrAliasTaken
+func (e *ErrAliasTaken) Error() string {
+	return fmt.Sprintf("l'alias '%s' est déjà utilisé", e.Alias)
+}
+
+// ErrInvalidDownPolicy est retournée lorsque la politique demandée pour une destination inaccessible
+// est inconnue ou incomplète (ex: "fallback" sans URL de secours).
+type ErrInvalidDownPolicy struct {
+	Policy string // La politique refusée
+	Reason string // La raison du refus
+}
+
+// Error implémente l'interface error pour ErrInvalidDownPolicy
+func (e *ErrInvalidDownPolicy) Error() string {
+	return fmt.Sprintf("politique '%s' invalide: %s", e.Policy, e.Reason)
+}
+
+// ErrInvalidAPIKey est retournée lorsqu'une clé d'API est inconnue ou révoquée.
+// Elle permet aux handlers HTTP de répondre avec un "401 Unauthorized".
+type ErrInvalidAPIKey struct{}
+
+// Error implémente l'interface error pour ErrInvalidAPIKey
+func (e *ErrInvalidAPIKey) Error() string {
+	return "clé d'API invalide ou révoquée"
+}
+
+// ErrAPIKeyNotFound est retournée lorsqu'aucune clé d'API active ne porte l'ID demandé.
+type ErrAPIKeyNotFound struct {
+	ID uint // L'ID recherché
+}
+
+// Error implémente l'interface error pour ErrAPIKeyNotFound
+func (e *ErrAPIKeyNotFound) Error() string {
+	return fmt.Sprintf("aucune clé d'API active avec l'ID %d", e.ID)
+}
+
+// ErrInvalidScope est retournée lorsqu'une permission demandée pour une clé d'API est inconnue.
+type ErrInvalidScope struct {
+	Scope string // La permission refusée
+}
+
+// Error implémente l'interface error pour ErrInvalidScope
+func (e *ErrInvalidScope) Error() string {
+	return fmt.Sprintf("permission inconnue '%s' (valeurs possibles : links:write, links:read, stats:read, admin)", e.Scope)
+}
+
+// ErrUserNotFound est retournée lorsqu'aucun utilisateur ne porte le nom demandé.
+type ErrUserNotFound struct {
+	Name string // Le nom recherché
+}
+
+// Error implémente l'interface error pour ErrUserNotFound
+func (e *ErrUserNotFound) Error() string {
+	return fmt.Sprintf("utilisateur '%s' introuvable", e.Name)
+}
+
+// ErrUserExists est retournée lorsqu'un utilisateur porte déjà le nom demandé.
+type ErrUserExists struct {
+	Name string // Le nom déjà pris
+}
+
+// Error implémente l'interface error pour ErrUserExists
+func (e *ErrUserExists) Error() string {
+	return fmt.Sprintf("l'utilisateur '%s' existe déjà", e.Name)
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Permissions (scopes) pouvant être accordées à une clé d'API.
+const (
+	ScopeLinksWrite = "links:write" // Créer, modifier et supprimer des liens
+	ScopeLinksRead  = "links:read"  // Lister les liens et consulter leur disponibilité
+	ScopeStatsRead  = "stats:read"  // Consulter les statistiques de clics
+	ScopeAdmin      = "admin"       // Toutes les permissions
+)
+
+// Scopes est la liste des permissions connues, dans l'ordre d'affichage.
+var Scopes = []string{ScopeLinksWrite, ScopeLinksRead, ScopeStatsRead, ScopeAdmin}
+
+// IsValidScope indique si scope est une permission connue.
+func IsValidScope(scope string) bool {
+	for _, known := range Scopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey représente une clé d'accès à l'API.
+// GORM utilisera ces tags pour créer la table 'api_keys'. La clé elle-même n'est jamais
+// stockée : seule son empreinte SHA-256 l'est, comme pour un mot de passe.
+type APIKey struct {
+	ID        uint   `gorm:"primaryKey"`             // Clé primaire
+	Name      string `gorm:"size:64;not null"`       // Nom donné à la clé (ex: "cms-production")
+	Prefix    string `gorm:"size:16;index;not null"` // Début de la clé, affiché pour la reconnaître
+	KeyHash   string `gorm:"size:64;uniqueIndex"`    // Empreinte SHA-256 (hexadécimale) de la clé
+	Scopes    string `gorm:"size:255;not null"`      // Permissions accordées, séparées par des virgules
+	CreatedAt time.Time
+
+	// UserID est l'utilisateur pour le compte duquel agit la clé : elle ne voit et ne crée
+	// que ses liens, sauf permission admin (nil = liens sans propriétaire)
+	UserID *uint `gorm:"index"`
+
+	// LastUsedAt est la date de dernière utilisation de la clé (nil = jamais utilisée)
+	LastUsedAt *time.Time
+
+	// RevokedAt est la date de révocation de la clé (nil = clé active)
+	RevokedAt *time.Time `gorm:"index"`
+}
+
+// ScopeList retourne les permissions de la clé.
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope indique si la clé accorde la permission scope (toujours vrai pour une clé admin).
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.ScopeList() {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRevoked indique si la clé a été révoquée.
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
@@ -11,6 +11,18 @@ type Click struct {
 	Timestamp time.Time // Horodatage précis du clic
 	UserAgent string    `gorm:"size:255"` // User-Agent de l'utilisateur qui a cliqué (informations sur le navigateur/OS)
 	IPAddress string    `gorm:"size:50"`  // Adresse IP de l'utilisateur
+
+	// Dimensions extraites du User-Agent à l'ingestion (voir analytics.ParseUserAgent)
+	Browser string `gorm:"size:32;index"` // Famille de navigateur (ex: "Chrome")
+	OS      string `gorm:"size:32;index"` // Famille de système d'exploitation (ex: "Android")
+	Device  string `gorm:"size:16;index"` // Classe d'appareil : desktop, mobile, tablet ou bot
+
+	// Referrer est l'hôte du site d'où provient le clic (en-tête Referer normalisé, vide = accès direct)
+	Referrer string `gorm:"size:255;index"`
+
+	// IsBot indique que le clic provient d'un robot (aperçu de lien, crawler, sonde...)
+	// Ces clics sont conservés mais exclus des statistiques par défaut
+	IsBot bool `gorm:"index;not null;default:false"`
 }
 
 // ClickEvent représente un événement de clic brut, destiné à être passé via un channel.
@@ -1,19 +1,23 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Link représente un lien raccourci dans la base de données.
 // Les tags `gorm:"..."` définissent comment GORM doit mapper cette structure à une table SQL.
 // GORM utilisera ces tags pour créer automatiquement la table 'links' avec les bonnes contraintes.
 type Link struct {
 	// ID est la clé primaire auto-incrémentée par GORM
-	ID uint `gorm:"primaryKey"`
+	ID uint `gorm:"primaryKey;index:idx_links_click_count,priority:2"`
 	
-	// ShortCode est le code court unique (ex: "abc123")
+	// ShortCode est le code court unique (ex: "abc123") ou un alias personnalisé (ex: "promo-ete")
 	// - unique : garantit qu'aucun doublon ne peut exister en BDD
 	// - index : crée un index pour des recherches rapides par ShortCode
-	// - size:10 : limite la longueur du champ VARCHAR en BDD à 10 caractères
-	ShortCode string `gorm:"unique;index;size:10"`
+	// - size:32 : limite la longueur du champ VARCHAR en BDD à 32 caractères (longueur max d'un alias)
+	ShortCode string `gorm:"unique;index;size:32"`
 	
 	// LongURL est l'URL originale complète à laquelle le ShortCode redirige
 	// - not null : ce champ est obligatoire, ne peut pas être vide
@@ -22,4 +26,100 @@ type Link struct {
 	// CreatedAt est l'horodatage de création du lien
 	// GORM gère automatiquement ce champ (le remplit à la création)
 	CreatedAt time.Time
+
+	// ExpiresAt est la date d'expiration optionnelle du lien (nil = le lien n'expire jamais)
+	// - index : permet au sweeper de retrouver rapidement les liens arrivés à échéance
+	ExpiresAt *time.Time `gorm:"index"`
+
+	// Expired est positionné à true par le sweeper une fois la date d'expiration dépassée
+	// (expiration "douce" : la ligne est conservée, avec son historique de clics)
+	Expired bool `gorm:"index;not null;default:false"`
+
+	// DeletedAt active la suppression "douce" de GORM : un lien supprimé reste en BDD
+	// (ce qui conserve son historique de clics) mais est exclu de toutes les requêtes.
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	// HealthStatus est l'état confirmé de la destination (unknown, up ou down), c'est-à-dire
+	// après application des seuils d'échecs et de succès consécutifs du moniteur.
+	// Il sert d'état de départ au moniteur après un redémarrage.
+	HealthStatus string `gorm:"size:16;index;not null;default:unknown"`
+
+	// FailureThreshold et RecoveryThreshold remplacent, pour ce lien, le nombre d'échecs
+	// (resp. de succès) consécutifs requis avant de le déclarer inaccessible (resp. accessible).
+	// nil = valeur globale de la configuration (monitor.failure_threshold / recovery_threshold).
+	FailureThreshold  *int
+	RecoveryThreshold *int
+
+	// LastCheckedAt est l'horodatage de la dernière vérification (nil = jamais vérifié)
+	LastCheckedAt *time.Time
+
+	// FinalURL est l'URL réellement atteinte, après redirections, lors de la dernière vérification
+	FinalURL string `gorm:"size:2048"`
+
+	// TLSStatus est l'état du dernier certificat TLS examiné par le moniteur (valid, expiring,
+	// invalid ; vide si aucun), TLSExpiresAt sa date d'expiration, TLSIssuer son émetteur et
+	// TLSHostnameValid indique s'il couvre le nom d'hôte de l'URL
+	TLSStatus        string `gorm:"size:16;index"`
+	TLSExpiresAt     *time.Time
+	TLSIssuer        string `gorm:"size:256"`
+	TLSHostnameValid *bool
+
+	// DownPolicy est le comportement de la redirection lorsque le moniteur a confirmé que la
+	// destination est inaccessible (voir DownPolicy*). La redirection normale reprend
+	// d'elle-même dès que le moniteur la déclare de nouveau accessible.
+	DownPolicy string `gorm:"size:16;not null;default:redirect"`
+
+	// FallbackURL est l'URL de secours utilisée avec la politique DownPolicyFallback
+	FallbackURL string `gorm:"size:2048"`
+
+	// OwnerID est l'ID de l'utilisateur propriétaire du lien (nil = lien sans propriétaire,
+	// visible seulement des clés d'API sans utilisateur et des administrateurs)
+	OwnerID *uint `gorm:"index;index:idx_links_owner_url_hash,priority:1"`
+
+	// URLHash est l'empreinte SHA-256 (hexadécimale) de l'URL longue normalisée.
+	// L'index composite (owner_id, url_hash) permet de retrouver le lien existant d'un propriétaire
+	// pour une même destination (déduplication à la création).
+	URLHash string `gorm:"size:64;index:idx_links_owner_url_hash,priority:2"`
+
+	// ClickCount est le nombre de clics hors robots, tenu à jour par l'enregistrement des clics
+	// (dénormalisé pour trier la liste des liens sans compter les clics de chaque lien).
+	// L'index composite (click_count, id) sert la pagination par curseur du tri par clics.
+	ClickCount int64 `gorm:"not null;default:0;index:idx_links_click_count,priority:1"`
+}
+
+// Politiques appliquées à la redirection d'un lien dont la destination est inaccessible (colonne Link.DownPolicy).
+const (
+	DownPolicyRedirect    = "redirect"    // Rediriger quand même vers l'URL longue (comportement par défaut)
+	DownPolicyFallback    = "fallback"    // Rediriger vers FallbackURL
+	DownPolicyUnavailable = "unavailable" // Afficher une page "destination indisponible" (503)
+)
+
+// IsValidDownPolicy indique si policy est une politique connue.
+func IsValidDownPolicy(policy string) bool {
+	switch policy {
+	case DownPolicyRedirect, DownPolicyFallback, DownPolicyUnavailable:
+		return true
+	}
+	return false
+}
+
+// ActiveDownPolicy retourne la politique à appliquer à la redirection en ce moment :
+// DownPolicyRedirect tant que la destination n'est pas confirmée inaccessible.
+func (l *Link) ActiveDownPolicy() string {
+	if l.HealthStatus != HealthDown || l.DownPolicy == "" {
+		return DownPolicyRedirect
+	}
+	if l.DownPolicy == DownPolicyFallback && l.FallbackURL == "" {
+		return DownPolicyRedirect
+	}
+	return l.DownPolicy
+}
+
+// IsExpired indique si le lien est expiré à l'instant donné.
+// On regarde aussi ExpiresAt directement pour ne pas dépendre du passage du sweeper.
+func (l *Link) IsExpired(now time.Time) bool {
+	if l.Expired {
+		return true
+	}
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
 }
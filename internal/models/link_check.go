@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// États de santé possibles d'un lien (colonne Link.HealthStatus).
+const (
+	HealthUnknown = "unknown" // Jamais vérifié
+	HealthUp      = "up"      // Dernière vérification réussie
+	HealthDown    = "down"    // Dernière vérification en échec
+)
+
+// Catégories d'échec d'une vérification (colonne LinkCheck.FailureKind).
+const (
+	FailureDNS              = "dns"                // Nom de domaine introuvable
+	FailureTLS              = "tls"                // Certificat invalide ou négociation TLS impossible
+	FailureTimeout          = "timeout"            // Pas de réponse dans le délai imparti
+	FailureConnection       = "connection"         // Connexion refusée ou interrompue
+	FailureHTTP4xx          = "http_4xx"           // Réponse 4xx
+	FailureHTTP5xx          = "http_5xx"           // Réponse 5xx
+	FailureHTTPOther        = "http_other"         // Autre code HTTP non considéré comme sain
+	FailureTooManyRedirects = "too_many_redirects" // Chaîne de redirections trop longue (ou boucle)
+	FailureInvalidURL       = "invalid_url"        // URL (ou redirection) mal formée
+	FailureOther            = "other"              // Autre erreur
+)
+
+// États du certificat TLS de la destination d'un lien (colonnes Link.TLSStatus et LinkCheck.TLSStatus).
+// Une valeur vide signifie qu'aucun certificat n'a été examiné (URL http, ou destination injoignable).
+const (
+	TLSValid    = "valid"    // Certificat valide, loin de son expiration
+	TLSExpiring = "expiring" // Certificat valide mais qui expire dans la fenêtre d'alerte
+	TLSInvalid  = "invalid"  // Certificat expiré, non reconnu ou ne couvrant pas le nom d'hôte
+)
+
+// LinkCheck représente une vérification de l'URL longue d'un lien par le moniteur.
+// GORM utilisera ces tags pour créer la table 'link_checks', qui constitue l'historique
+// de disponibilité de chaque destination.
+type LinkCheck struct {
+	ID         uint      `gorm:"primaryKey"`                      // Clé primaire
+	LinkID     uint      `gorm:"index:idx_link_checks_link_time"` // Clé étrangère vers la table 'links'
+	Link       Link      `gorm:"foreignKey:LinkID"`               // Relation GORM vers le lien vérifié
+	CheckedAt  time.Time `gorm:"index:idx_link_checks_link_time"` // Horodatage (UTC) de la vérification
+	Healthy    bool      `gorm:"not null"`                        // Résultat : destination accessible ou non
+	Method     string    `gorm:"size:8"`                          // Méthode de la requête finale : HEAD, ou GET si le serveur refuse HEAD
+	StatusCode int       // Code HTTP final reçu (0 si aucune réponse)
+	LatencyMs  int64     // Durée de la vérification (redirections comprises) en millisecondes
+	Error      string    `gorm:"size:512"` // Erreur réseau éventuelle (vide si une réponse a été reçue)
+
+	// FailureKind est la catégorie d'échec (dns, tls, timeout, http_4xx...), vide si la vérification a réussi
+	FailureKind string `gorm:"size:32;index"`
+
+	// FinalURL est l'URL atteinte au bout de la chaîne de redirections
+	FinalURL string `gorm:"size:2048"`
+
+	// RedirectChain est la liste JSON des redirections suivies ([{"url": ..., "status_code": ...}]), vide sans redirection
+	RedirectChain string `gorm:"type:text"`
+
+	// Certificat présenté par la dernière destination HTTPS de la chaîne (colonnes vides si aucun)
+	TLSStatus        string     `gorm:"size:16"` // valid, expiring ou invalid (voir TLS*)
+	TLSExpiresAt     *time.Time // Date d'expiration (NotAfter) du certificat
+	TLSIssuer        string     `gorm:"size:256"` // Émetteur du certificat
+	TLSHostnameValid *bool      // Le certificat couvre-t-il le nom d'hôte de l'URL ?
+	TLSError         string     `gorm:"size:512"` // Raison pour laquelle le certificat est invalide
+}
@@ -0,0 +1,16 @@
+package models
+
+import "unicode/utf8"
+
+// Truncate coupe s à au plus max octets sans couper un caractère UTF-8. Elle sert à borner
+// les valeurs fournies par l'extérieur (en-têtes HTTP, erreurs réseau, URL) avant de les
+// stocker dans une colonne de taille limitée.
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,22 @@
+package models
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"héllo", 2, "h"}, // "é" occupe deux octets : il n'est pas coupé en deux
+		{"héllo", 3, "hé"},
+		{"日本", 2, ""},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.in, tt.max); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, attendu %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// User représente un utilisateur (ou une équipe) propriétaire de liens.
+// GORM utilisera ces tags pour créer la table 'users'.
+type User struct {
+	ID        uint      `gorm:"primaryKey"`                   // Clé primaire
+	Name      string    `gorm:"size:64;uniqueIndex;not null"` // Nom unique de l'utilisateur (ex: "marketing")
+	CreatedAt time.Time // Horodatage de création
+}
@@ -0,0 +1,253 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/analytics" // Pour le User-Agent du moniteur
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// defaultMaxRedirects est le nombre maximum de redirections suivies si la configuration ne le précise pas.
+const defaultMaxRedirects = 10
+
+// headFallbackStatus sont les réponses à un HEAD qui déclenchent une nouvelle tentative en GET :
+// beaucoup de sites refusent HEAD alors que la page fonctionne.
+var headFallbackStatus = map[int]bool{
+	http.StatusBadRequest:       true,
+	http.StatusForbidden:        true,
+	http.StatusNotFound:         true,
+	http.StatusMethodNotAllowed: true,
+	http.StatusNotImplemented:   true,
+}
+
+// StatusSet est un ensemble de codes HTTP, décrit par des codes ou des plages (ex: "200-299", "401").
+type StatusSet struct {
+	ranges [][2]int
+}
+
+// defaultHealthyStatus correspond au comportement historique : 2xx et 3xx sont sains.
+var defaultHealthyStatus = StatusSet{ranges: [][2]int{{200, 399}}}
+
+// ParseStatusSet construit un StatusSet à partir de codes ("404") ou de plages ("200-399").
+func ParseStatusSet(specs []string) (StatusSet, error) {
+	var set StatusSet
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		low, high, isRange := strings.Cut(spec, "-")
+		if !isRange {
+			high = low
+		}
+		lo, errLo := strconv.Atoi(strings.TrimSpace(low))
+		hi, errHi := strconv.Atoi(strings.TrimSpace(high))
+		if errLo != nil || errHi != nil || lo < 100 || hi > 599 || lo > hi {
+			return StatusSet{}, fmt.Errorf("code ou plage de codes HTTP invalide : %q", spec)
+		}
+		set.ranges = append(set.ranges, [2]int{lo, hi})
+	}
+	return set, nil
+}
+
+// Contains indique si code appartient à l'ensemble.
+func (s StatusSet) Contains(code int) bool {
+	for _, r := range s.ranges {
+		if code >= r[0] && code <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty indique si l'ensemble ne contient aucun code.
+func (s StatusSet) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Hop est une étape de la chaîne de redirections suivie lors d'une vérification.
+type Hop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// checkResult est le résultat d'une vérification d'URL.
+type checkResult struct {
+	Healthy     bool
+	Method      string        // Méthode de la dernière requête (HEAD, ou GET après repli)
+	StatusCode  int           // Code HTTP final (0 si aucune réponse n'a été reçue)
+	Latency     time.Duration // Durée totale de la vérification, redirections comprises
+	FailureKind string        // Catégorie d'échec (voir models.Failure*), vide si sain
+	Error       string        // Erreur réseau éventuelle
+	FinalURL    string        // URL atteinte au bout des redirections
+	Redirects   []Hop         // Redirections suivies (sans la réponse finale)
+	Certificate *certInfo     // Certificat de la dernière destination HTTPS contactée (nil si aucune)
+	CheckedAt   time.Time     // Début de la vérification (UTC)
+}
+
+// newCheckClient crée le client HTTP des vérifications. Les redirections ne sont pas suivies
+// automatiquement : followRedirects les suit elle-même pour enregistrer la chaîne.
+func newCheckClient() *http.Client {
+	return &http.Client{
+		// Définir un timeout pour éviter de bloquer trop longtemps (5 secondes c'est bien)
+		Timeout: 5 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isUrlAccessible vérifie l'accessibilité d'une URL : requête HEAD (plus légère), puis GET
+// partiel (un seul octet) si le serveur refuse HEAD. Les redirections sont suivies dans la
+// limite de opts.MaxRedirects. La requête est annulée si ctx l'est.
+func (m *UrlMonitor) isUrlAccessible(ctx context.Context, rawURL string) checkResult {
+	start := time.Now()
+	result := m.followRedirects(ctx, http.MethodHead, rawURL)
+	if result.Error == "" && headFallbackStatus[result.StatusCode] {
+		result = m.followRedirects(ctx, http.MethodGet, rawURL)
+	}
+	result.CheckedAt = start.UTC()
+	result.Latency = time.Since(start)
+
+	if result.Error != "" {
+		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s' (%s): %s", rawURL, result.FailureKind, result.Error)
+	}
+	return result
+}
+
+// followRedirects envoie une requête method à rawURL et suit les redirections.
+func (m *UrlMonitor) followRedirects(ctx context.Context, method, rawURL string) checkResult {
+	result := checkResult{Method: method, FinalURL: rawURL}
+
+	current := rawURL
+	for hop := 0; ; hop++ {
+		resp, err := m.send(ctx, method, current)
+		if err != nil {
+			result.FailureKind, result.Error = classifyError(err), err.Error()
+			if isCertificateError(err) {
+				// Le client refuse le certificat : le récupérer à part pour savoir pourquoi.
+				result.Certificate = inspectCertificate(ctx, m.client, current)
+			}
+			return result
+		}
+		resp.Body.Close()
+		if cert := certFromState(resp.TLS); cert != nil {
+			result.Certificate = cert
+		}
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || resp.StatusCode == http.StatusNotModified || location == "" {
+			// Réponse finale : déterminer l'accessibilité basée sur le code de statut HTTP.
+			result.StatusCode = resp.StatusCode
+			result.Healthy = m.opts.HealthyStatus.Contains(resp.StatusCode)
+			if !result.Healthy {
+				result.FailureKind = classifyStatus(resp.StatusCode)
+			}
+			return result
+		}
+
+		result.Redirects = append(result.Redirects, Hop{URL: current, StatusCode: resp.StatusCode})
+		if hop >= m.opts.MaxRedirects {
+			result.StatusCode = resp.StatusCode
+			result.FailureKind = models.FailureTooManyRedirects
+			result.Error = fmt.Sprintf("plus de %d redirections", m.opts.MaxRedirects)
+			return result
+		}
+
+		next, err := resp.Request.URL.Parse(location)
+		if err != nil {
+			result.StatusCode = resp.StatusCode
+			result.FailureKind = models.FailureInvalidURL
+			result.Error = fmt.Sprintf("redirection invalide %q : %v", location, err)
+			return result
+		}
+		current = next.String()
+		result.FinalURL = current
+	}
+}
+
+// send envoie une requête sans suivre les redirections. Un GET ne demande que le premier octet.
+func (m *UrlMonitor) send(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Le User-Agent dédié permet de reconnaître (et d'exclure des statistiques) nos propres requêtes.
+	req.Header.Set("User-Agent", analytics.MonitorUserAgent)
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		// Un serveur qui ignore Range renvoie toute la page : ne lire qu'un peu avant de fermer.
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	}
+	return resp, nil
+}
+
+// classifyError range une erreur de requête dans une catégorie d'échec.
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var urlErr *url.Error
+	var netErr net.Error
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return models.FailureDNS
+	case isCertificateError(err), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return models.FailureTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return models.FailureTimeout
+	case errors.As(err, &urlErr) && urlErr.Op == "parse":
+		return models.FailureInvalidURL
+	case errors.As(err, &urlErr) && isConnectionError(urlErr.Err):
+		return models.FailureConnection
+	default:
+		return models.FailureOther
+	}
+}
+
+// isConnectionError indique si err est une erreur d'établissement ou de maintien de connexion.
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// classifyStatus range un code HTTP non sain dans une catégorie d'échec.
+func classifyStatus(code int) string {
+	switch {
+	case code >= 500:
+		return models.FailureHTTP5xx
+	case code >= 400:
+		return models.FailureHTTP4xx
+	default:
+		return models.FailureHTTPOther
+	}
+}
+
+// encodeRedirectChain sérialise la chaîne de redirections pour la colonne link_checks.redirect_chain.
+func encodeRedirectChain(hops []Hop) string {
+	if len(hops) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(hops)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
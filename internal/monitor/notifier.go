@@ -0,0 +1,243 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Événements transmis aux notifiers (champ "event" des notifications JSON).
+const (
+	EventStateChanged       = "link.state_changed"       // La destination est devenue accessible ou inaccessible
+	EventCertificateChanged = "link.certificate_changed" // Le certificat TLS de la destination expire bientôt, est invalide, ou est de nouveau valide
+)
+
+// StateChange décrit le changement d'état de la destination d'un lien, transmis aux Notifier.
+// Pour un événement EventCertificateChanged, Previous et Current sont des états de certificat
+// (models.TLS*, Previous pouvant être vide) et Certificate décrit le certificat examiné.
+type StateChange struct {
+	Event       string           `json:"-"` // EventStateChanged (par défaut) ou EventCertificateChanged
+	LinkID      uint             `json:"link_id"`
+	ShortCode   string           `json:"short_code"`
+	LongURL     string           `json:"long_url"`
+	Previous    string           `json:"previous_state"` // models.HealthUp ou models.HealthDown
+	Current     string           `json:"current_state"`  // models.HealthUp ou models.HealthDown
+	StatusCode  int              `json:"status_code,omitempty"`
+	FailureKind string           `json:"failure_kind,omitempty"` // Catégorie d'échec (voir models.Failure*)
+	Error       string           `json:"error,omitempty"`
+	Certificate *CertificateInfo `json:"certificate,omitempty"`
+	CheckedAt   time.Time        `json:"checked_at"`
+}
+
+// CertificateInfo décrit le certificat TLS d'une destination dans une notification.
+type CertificateInfo struct {
+	Subject       string    `json:"subject,omitempty"`
+	Issuer        string    `json:"issuer"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	DaysLeft      int       `json:"days_left"` // Négatif si le certificat a expiré
+	HostnameValid bool      `json:"hostname_valid"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// EventName retourne l'événement de la notification (EventStateChanged si Event est vide).
+func (c StateChange) EventName() string {
+	if c.Event == "" {
+		return EventStateChanged
+	}
+	return c.Event
+}
+
+// Summary retourne une description d'une ligne du changement d'état, utilisée par les notifiers textuels.
+func (c StateChange) Summary() string {
+	if c.EventName() == EventCertificateChanged && c.Certificate != nil {
+		switch c.Current {
+		case models.TLSExpiring:
+			return fmt.Sprintf("Le certificat TLS du lien %s (%s) expire dans %d jour(s), le %s",
+				c.ShortCode, c.LongURL, c.Certificate.DaysLeft, c.Certificate.ExpiresAt.Format("2006-01-02"))
+		case models.TLSInvalid:
+			return fmt.Sprintf("Le certificat TLS du lien %s (%s) est invalide", c.ShortCode, c.LongURL)
+		default:
+			return fmt.Sprintf("Le certificat TLS du lien %s (%s) est de nouveau valide, jusqu'au %s",
+				c.ShortCode, c.LongURL, c.Certificate.ExpiresAt.Format("2006-01-02"))
+		}
+	}
+	return fmt.Sprintf("Le lien %s (%s) est passé de %s à %s", c.ShortCode, c.LongURL,
+		formatState(c.Previous == models.HealthUp), formatState(c.Current == models.HealthUp))
+}
+
+// Headline retourne l'état courant en quelques mots, utilisé dans les titres (objet des e-mails...).
+func (c StateChange) Headline() string {
+	if c.EventName() == EventCertificateChanged {
+		switch c.Current {
+		case models.TLSExpiring:
+			return "CERTIFICAT BIENTÔT EXPIRÉ"
+		case models.TLSInvalid:
+			return "CERTIFICAT INVALIDE"
+		default:
+			return "CERTIFICAT VALIDE"
+		}
+	}
+	return formatState(c.Current == models.HealthUp)
+}
+
+// IsAlert indique si le changement signale un problème (et non un retour à la normale).
+func (c StateChange) IsAlert() bool {
+	if c.EventName() == EventCertificateChanged {
+		return c.Current != models.TLSValid
+	}
+	return c.Current == models.HealthDown
+}
+
+// Notifier est un canal de notification des changements d'état (webhook, e-mail, Slack, fichier...).
+// Notify doit respecter l'annulation de ctx. Une erreur enveloppée par Permanent n'est pas retentée.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, change StateChange) error
+}
+
+// permanentError marque une erreur qu'il est inutile de retenter (ex: webhook qui répond 400).
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent enveloppe err pour indiquer au Dispatcher de ne pas retenter l'envoi.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// RetryPolicy définit les nouvelles tentatives d'envoi d'une notification.
+// Le délai entre deux tentatives double à chaque échec, dans la limite de MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int           // Nombre total de tentatives (1 = pas de nouvelle tentative)
+	InitialBackoff time.Duration // Délai avant la deuxième tentative
+	MaxBackoff     time.Duration // Délai maximum entre deux tentatives
+}
+
+// Valeurs par défaut des nouvelles tentatives, utilisées si la configuration est absente ou invalide.
+const (
+	defaultNotifyAttempts   = 3
+	defaultNotifyBackoff    = time.Second
+	defaultNotifyMaxBackoff = 30 * time.Second
+)
+
+// Dispatcher envoie chaque changement d'état à tous les notifiers, en parallèle et en arrière-plan,
+// avec nouvelles tentatives. Un notifier en échec ne retarde ni les autres ni les vérifications.
+type Dispatcher struct {
+	notifiers []Notifier
+	retry     RetryPolicy
+	wg        sync.WaitGroup
+}
+
+// NewDispatcher crée un Dispatcher pour les notifiers donnés.
+func NewDispatcher(notifiers []Notifier, retry RetryPolicy) *Dispatcher {
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = defaultNotifyAttempts
+	}
+	if retry.InitialBackoff <= 0 {
+		retry.InitialBackoff = defaultNotifyBackoff
+	}
+	if retry.MaxBackoff < retry.InitialBackoff {
+		retry.MaxBackoff = defaultNotifyMaxBackoff
+		if retry.MaxBackoff < retry.InitialBackoff {
+			retry.MaxBackoff = retry.InitialBackoff
+		}
+	}
+	return &Dispatcher{notifiers: notifiers, retry: retry}
+}
+
+// Dispatch lance l'envoi de change à tous les notifiers et rend la main immédiatement.
+// Les envois en cours sont abandonnés à l'annulation de ctx.
+func (d *Dispatcher) Dispatch(ctx context.Context, change StateChange) {
+	for _, n := range d.notifiers {
+		d.wg.Add(1)
+		go func(n Notifier) {
+			defer d.wg.Done()
+			d.send(ctx, n, change)
+		}(n)
+	}
+}
+
+// Wait attend la fin des envois lancés par Dispatch.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// send envoie change à un notifier en appliquant la politique de nouvelles tentatives.
+func (d *Dispatcher) send(ctx context.Context, n Notifier, change StateChange) {
+	backoff := d.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := n.Notify(ctx, change)
+		if err == nil {
+			return
+		}
+
+		var permErr *permanentError
+		if errors.As(err, &permErr) || attempt >= d.retry.MaxAttempts {
+			log.Printf("[MONITOR] ERREUR : notification %s abandonnée pour le lien %s après %d tentative(s) : %v",
+				n.Name(), change.ShortCode, attempt, err)
+			return
+		}
+		log.Printf("[MONITOR] Échec de la notification %s pour le lien %s (tentative %d/%d), nouvel essai dans %v : %v",
+			n.Name(), change.ShortCode, attempt, d.retry.MaxAttempts, backoff, err)
+
+		if !sleepCtx(ctx, backoff) {
+			log.Printf("[MONITOR] Notification %s abandonnée pour le lien %s : arrêt du moniteur.", n.Name(), change.ShortCode)
+			return
+		}
+		backoff *= 2
+		if backoff > d.retry.MaxBackoff {
+			backoff = d.retry.MaxBackoff
+		}
+	}
+}
+
+// NotifiersFromConfig construit les notifiers activés dans la section monitor.notifiers de la configuration.
+func NotifiersFromConfig(cfg config.NotifiersConfig) ([]Notifier, error) {
+	var notifiers []Notifier
+
+	if cfg.Webhook.Enabled {
+		if cfg.Webhook.URL == "" {
+			return nil, fmt.Errorf("monitor.notifiers.webhook : url requise")
+		}
+		notifiers = append(notifiers, NewWebhookNotifier(cfg.Webhook.URL, cfg.Webhook.Secret, nil))
+	}
+	if cfg.Slack.Enabled {
+		if cfg.Slack.WebhookURL == "" {
+			return nil, fmt.Errorf("monitor.notifiers.slack : webhook_url requise")
+		}
+		notifiers = append(notifiers, NewSlackNotifier(cfg.Slack.WebhookURL, nil))
+	}
+	if cfg.Email.Enabled {
+		if cfg.Email.Host == "" || cfg.Email.From == "" || len(cfg.Email.To) == 0 {
+			return nil, fmt.Errorf("monitor.notifiers.email : host, from et to requis")
+		}
+		notifiers = append(notifiers, NewSMTPNotifier(SMTPOptions{
+			Host:     cfg.Email.Host,
+			Port:     cfg.Email.Port,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+			To:       cfg.Email.To,
+		}))
+	}
+	if cfg.File.Enabled {
+		notifier, err := NewFileNotifier(cfg.File.Path)
+		if err != nil {
+			return nil, fmt.Errorf("monitor.notifiers.file : %w", err)
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, nil
+}
@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileNotifier écrit chaque changement d'état sous forme d'une ligne JSON dans un fichier
+// (ou sur la sortie standard), pour être consommé par un collecteur de logs.
+type FileNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewFileNotifier crée un FileNotifier. Si path est vide ou vaut "-", les notifications
+// sont écrites sur la sortie standard ; sinon elles sont ajoutées à la fin du fichier.
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	if path == "" || path == "-" {
+		return &FileNotifier{w: os.Stdout}, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("impossible d'ouvrir %s : %w", path, err)
+	}
+	return &FileNotifier{w: f}, nil
+}
+
+// Name retourne le nom du notifier, utilisé dans les logs.
+func (n *FileNotifier) Name() string { return "file" }
+
+// Notify écrit le changement d'état.
+func (n *FileNotifier) Notify(ctx context.Context, change StateChange) error {
+	line, err := json.Marshal(webhookPayload{Event: change.EventName(), StateChange: change})
+	if err != nil {
+		return Permanent(fmt.Errorf("impossible d'encoder la notification : %w", err))
+	}
+	line = append(line, '\n')
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err = n.w.Write(line)
+	return err
+}
@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/analytics"
+)
+
+// notifierHTTPTimeout borne la durée d'un envoi HTTP (webhook, Slack).
+const notifierHTTPTimeout = 10 * time.Second
+
+// SignatureHeader est l'en-tête portant la signature HMAC-SHA256 du corps des webhooks,
+// au format "sha256=<hex>". Le destinataire la recalcule avec le secret partagé pour
+// s'assurer que la notification provient bien du service.
+const SignatureHeader = "X-Urlshortener-Signature"
+
+// WebhookNotifier envoie chaque changement d'état en JSON (POST) à une URL, signé par HMAC.
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier crée un WebhookNotifier. Si secret est vide, le corps n'est pas signé.
+// client peut être nil (client par défaut avec un timeout de 10 secondes).
+func NewWebhookNotifier(url, secret string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: notifierHTTPTimeout}
+	}
+	return &WebhookNotifier{url: url, secret: secret, client: client}
+}
+
+// Name retourne le nom du notifier, utilisé dans les logs.
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+// webhookPayload est le corps JSON envoyé par WebhookNotifier.
+type webhookPayload struct {
+	Event string `json:"event"`
+	StateChange
+}
+
+// Notify envoie le changement d'état au webhook.
+func (n *WebhookNotifier) Notify(ctx context.Context, change StateChange) error {
+	body, err := json.Marshal(webhookPayload{Event: change.EventName(), StateChange: change})
+	if err != nil {
+		return Permanent(fmt.Errorf("impossible d'encoder la notification : %w", err))
+	}
+
+	headers := map[string]string{}
+	if n.secret != "" {
+		headers[SignatureHeader] = "sha256=" + Sign(n.secret, body)
+	}
+	return postJSON(ctx, n.client, n.url, body, headers)
+}
+
+// Sign calcule la signature HMAC-SHA256 (hexadécimale) de body avec secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SlackNotifier envoie chaque changement d'état à un webhook entrant compatible Slack
+// (Slack, Mattermost, Rocket.Chat...).
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackNotifier crée un SlackNotifier. client peut être nil (client par défaut avec un timeout de 10 secondes).
+func NewSlackNotifier(webhookURL string, client *http.Client) *SlackNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: notifierHTTPTimeout}
+	}
+	return &SlackNotifier{webhookURL: webhookURL, client: client}
+}
+
+// Name retourne le nom du notifier, utilisé dans les logs.
+func (n *SlackNotifier) Name() string { return "slack" }
+
+// Notify envoie le changement d'état au webhook entrant.
+func (n *SlackNotifier) Notify(ctx context.Context, change StateChange) error {
+	icon := ":white_check_mark:"
+	if change.IsAlert() {
+		icon = ":rotating_light:"
+	}
+	text := fmt.Sprintf("%s %s.", icon, change.Summary())
+	if change.Certificate != nil && change.Certificate.Error != "" {
+		text += fmt.Sprintf("\nErreur : %s", change.Certificate.Error)
+	} else if change.Error != "" {
+		text += fmt.Sprintf("\nErreur : %s", change.Error)
+	} else if change.StatusCode != 0 {
+		text += fmt.Sprintf("\nCode HTTP : %d", change.StatusCode)
+	}
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return Permanent(fmt.Errorf("impossible d'encoder la notification : %w", err))
+	}
+	return postJSON(ctx, n.client, n.webhookURL, body, nil)
+}
+
+// postJSON envoie body en POST à url. Les erreurs réseau et les réponses 429 / 5xx peuvent
+// être retentées ; les autres réponses hors 2xx sont des erreurs permanentes.
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("requête de notification invalide : %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", analytics.MonitorUserAgent)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Lire (au plus 1 Kio) et ignorer le corps pour permettre la réutilisation de la connexion.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("réponse HTTP %d de %s", resp.StatusCode, url)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return Permanent(err)
+}
@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPOptions regroupe les paramètres d'envoi des notifications par e-mail.
+type SMTPOptions struct {
+	Host     string
+	Port     int // 587 par défaut
+	Username string
+	Password string // Authentification PLAIN si Username est renseigné
+	From     string
+	To       []string
+}
+
+// SMTPNotifier envoie chaque changement d'état par e-mail.
+// STARTTLS est utilisé automatiquement si le serveur le propose.
+type SMTPNotifier struct {
+	opts SMTPOptions
+}
+
+// NewSMTPNotifier crée un SMTPNotifier.
+func NewSMTPNotifier(opts SMTPOptions) *SMTPNotifier {
+	if opts.Port <= 0 {
+		opts.Port = 587
+	}
+	return &SMTPNotifier{opts: opts}
+}
+
+// Name retourne le nom du notifier, utilisé dans les logs.
+func (n *SMTPNotifier) Name() string { return "email" }
+
+// Notify envoie le changement d'état par e-mail. net/smtp ne prend pas de contexte :
+// l'envoi est lancé dans une goroutine et abandonné (sans être interrompu) si ctx est annulé.
+func (n *SMTPNotifier) Notify(ctx context.Context, change StateChange) error {
+	addr := net.JoinHostPort(n.opts.Host, strconv.Itoa(n.opts.Port))
+	var auth smtp.Auth
+	if n.opts.Username != "" {
+		auth = smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)
+	}
+	msg := n.message(change)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, n.opts.From, n.opts.To, msg)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// message construit l'e-mail (en-têtes et corps en texte brut UTF-8).
+func (n *SMTPNotifier) message(change StateChange) []byte {
+	subject := fmt.Sprintf("[urlshortener] %s : %s", change.ShortCode, change.Headline())
+
+	var body strings.Builder
+	body.WriteString(change.Summary() + ".\r\n\r\n")
+	fmt.Fprintf(&body, "Vérifié le : %s\r\n", change.CheckedAt.Format(time.RFC1123Z))
+	if change.StatusCode != 0 {
+		fmt.Fprintf(&body, "Code HTTP : %d\r\n", change.StatusCode)
+	}
+	if change.Error != "" {
+		fmt.Fprintf(&body, "Erreur : %s\r\n", change.Error)
+	}
+	if cert := change.Certificate; cert != nil {
+		fmt.Fprintf(&body, "Certificat émis par : %s\r\n", cert.Issuer)
+		fmt.Fprintf(&body, "Expiration : %s\r\n", cert.ExpiresAt.Format(time.RFC1123Z))
+		if cert.Error != "" {
+			fmt.Fprintf(&body, "Problème : %s\r\n", cert.Error)
+		}
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.opts.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.opts.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(body.String())
+	return []byte(msg.String())
+}
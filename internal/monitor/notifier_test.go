@@ -0,0 +1,323 @@
+package monitor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// testChange est le changement d'état envoyé par les tests.
+var testChange = StateChange{
+	LinkID:      42,
+	ShortCode:   "abc123",
+	LongURL:     "https://example.com/page",
+	Previous:    models.HealthUp,
+	Current:     models.HealthDown,
+	StatusCode:  503,
+	FailureKind: models.FailureHTTP5xx,
+	CheckedAt:   time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// recordedRequest est une requête reçue par le serveur de test.
+type recordedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newNotifyServer démarre un serveur HTTP local qui enregistre les requêtes reçues et répond
+// avec le code retourné par status (appelée avec le numéro de la requête, à partir de 1).
+func newNotifyServer(t *testing.T, status func(n int) int) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, recordedRequest{header: r.Header.Clone(), body: body})
+		n := len(requests)
+		mu.Unlock()
+		w.WriteHeader(status(n))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+// always retourne une fonction de statut constante pour newNotifyServer.
+func always(status int) func(int) int {
+	return func(int) int { return status }
+}
+
+// fastRetry est une politique de nouvelles tentatives sans attente notable.
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+func TestWebhookNotifierSignsJSONBody(t *testing.T) {
+	server, requests := newNotifyServer(t, always(http.StatusOK))
+
+	notifier := NewWebhookNotifier(server.URL, "s3cret", server.Client())
+	if err := notifier.Notify(context.Background(), testChange); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("%d requête(s) reçue(s), attendu 1", len(got))
+	}
+	req := got[0]
+	if ct := req.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(req.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if sig := req.header.Get(SignatureHeader); sig != want {
+		t.Errorf("signature = %q, attendu %q", sig, want)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("corps JSON illisible: %v", err)
+	}
+	expected := map[string]any{
+		"event":          EventStateChanged,
+		"link_id":        float64(42),
+		"short_code":     "abc123",
+		"long_url":       "https://example.com/page",
+		"previous_state": models.HealthUp,
+		"current_state":  models.HealthDown,
+		"status_code":    float64(503),
+		"failure_kind":   models.FailureHTTP5xx,
+		"checked_at":     "2025-06-01T12:00:00Z",
+	}
+	for key, value := range expected {
+		if payload[key] != value {
+			t.Errorf("%s = %v, attendu %v", key, payload[key], value)
+		}
+	}
+}
+
+func TestWebhookNotifierWithoutSecretIsUnsigned(t *testing.T) {
+	server, requests := newNotifyServer(t, always(http.StatusNoContent))
+
+	notifier := NewWebhookNotifier(server.URL, "", server.Client())
+	if err := notifier.Notify(context.Background(), testChange); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if sig := requests()[0].header.Get(SignatureHeader); sig != "" {
+		t.Errorf("signature inattendue %q sans secret", sig)
+	}
+}
+
+func TestSlackNotifierPayload(t *testing.T) {
+	server, requests := newNotifyServer(t, always(http.StatusOK))
+
+	notifier := NewSlackNotifier(server.URL, server.Client())
+	if err := notifier.Notify(context.Background(), testChange); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(requests()[0].body, &payload); err != nil {
+		t.Fatalf("corps JSON illisible: %v", err)
+	}
+	if len(payload) != 1 {
+		t.Errorf("champs = %v, attendu le seul champ text", payload)
+	}
+	text, _ := payload["text"].(string)
+	for _, part := range []string{":rotating_light:", testChange.Summary(), "Code HTTP : 503"} {
+		if !strings.Contains(text, part) {
+			t.Errorf("text = %q, ne contient pas %q", text, part)
+		}
+	}
+}
+
+func TestFileNotifierWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier, err := NewFileNotifier(path)
+	if err != nil {
+		t.Fatalf("NewFileNotifier: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify(context.Background(), testChange); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("lecture: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("%d ligne(s), attendu 2", len(lines))
+	}
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &payload); err != nil {
+		t.Fatalf("ligne JSON illisible: %v", err)
+	}
+	if payload["event"] != EventStateChanged || payload["short_code"] != "abc123" {
+		t.Errorf("ligne = %v", payload)
+	}
+}
+
+func TestSMTPNotifierMessage(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPOptions{Host: "smtp.example.com", From: "monitor@example.com", To: []string{"a@example.com", "b@example.com"}})
+	msg := string(notifier.message(testChange))
+
+	for _, part := range []string{
+		"From: monitor@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		testChange.Summary(),
+		"Code HTTP : 503",
+	} {
+		if !strings.Contains(msg, part) {
+			t.Errorf("message ne contient pas %q:\n%s", part, msg)
+		}
+	}
+}
+
+func TestDispatcherRetriesServerErrors(t *testing.T) {
+	// Deux réponses 503, puis un succès.
+	server, requests := newNotifyServer(t, func(n int) int {
+		if n <= 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+
+	d := NewDispatcher([]Notifier{NewWebhookNotifier(server.URL, "", server.Client())}, fastRetry)
+	d.Dispatch(context.Background(), testChange)
+	d.Wait()
+
+	if n := len(requests()); n != 3 {
+		t.Errorf("%d requête(s), attendu 3", n)
+	}
+}
+
+func TestDispatcherDoesNotRetryClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound} {
+		server, requests := newNotifyServer(t, always(status))
+
+		d := NewDispatcher([]Notifier{NewWebhookNotifier(server.URL, "", server.Client())}, fastRetry)
+		d.Dispatch(context.Background(), testChange)
+		d.Wait()
+
+		if n := len(requests()); n != 1 {
+			t.Errorf("HTTP %d : %d requête(s), attendu 1", status, n)
+		}
+	}
+}
+
+func TestDispatcherRetriesTooManyRequests(t *testing.T) {
+	server, requests := newNotifyServer(t, func(n int) int {
+		if n == 1 {
+			return http.StatusTooManyRequests
+		}
+		return http.StatusOK
+	})
+
+	d := NewDispatcher([]Notifier{NewSlackNotifier(server.URL, server.Client())}, fastRetry)
+	d.Dispatch(context.Background(), testChange)
+	d.Wait()
+
+	if n := len(requests()); n != 2 {
+		t.Errorf("%d requête(s), attendu 2", n)
+	}
+}
+
+func TestDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+	server, requests := newNotifyServer(t, always(http.StatusInternalServerError))
+
+	retry := fastRetry
+	retry.MaxAttempts = 4
+	d := NewDispatcher([]Notifier{NewWebhookNotifier(server.URL, "", server.Client())}, retry)
+	d.Dispatch(context.Background(), testChange)
+	d.Wait()
+
+	if n := len(requests()); n != 4 {
+		t.Errorf("%d requête(s), attendu 4", n)
+	}
+}
+
+// funcNotifier est un Notifier défini par une fonction, qui compte ses appels.
+type funcNotifier struct {
+	calls  atomic.Int32
+	notify func(ctx context.Context) error
+}
+
+func (n *funcNotifier) Name() string { return "test" }
+
+func (n *funcNotifier) Notify(ctx context.Context, change StateChange) error {
+	n.calls.Add(1)
+	return n.notify(ctx)
+}
+
+func TestDispatcherDoesNotRetryPermanentErrors(t *testing.T) {
+	notifier := &funcNotifier{notify: func(context.Context) error {
+		return Permanent(errors.New("destinataire invalide"))
+	}}
+
+	d := NewDispatcher([]Notifier{notifier}, fastRetry)
+	d.Dispatch(context.Background(), testChange)
+	d.Wait()
+
+	if n := notifier.calls.Load(); n != 1 {
+		t.Errorf("%d appel(s), attendu 1", n)
+	}
+}
+
+func TestDispatcherAbandonsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	notifier := &funcNotifier{notify: func(context.Context) error {
+		cancel() // Arrêt du moniteur pendant l'attente avant la nouvelle tentative
+		return errors.New("connexion refusée")
+	}}
+
+	// Un délai d'une heure : seule l'annulation peut interrompre l'attente.
+	d := NewDispatcher([]Notifier{notifier}, RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+	d.Dispatch(ctx, testChange)
+
+	done := make(chan struct{})
+	go func() {
+		d.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("l'envoi n'a pas été abandonné à l'annulation du contexte")
+	}
+	if n := notifier.calls.Load(); n != 1 {
+		t.Errorf("%d appel(s), attendu 1", n)
+	}
+}
+
+func TestNewDispatcherDefaults(t *testing.T) {
+	d := NewDispatcher(nil, RetryPolicy{})
+	want := RetryPolicy{MaxAttempts: defaultNotifyAttempts, InitialBackoff: defaultNotifyBackoff, MaxBackoff: defaultNotifyMaxBackoff}
+	if d.retry != want {
+		t.Errorf("retry = %+v, attendu %+v", d.retry, want)
+	}
+}
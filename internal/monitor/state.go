@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Valeurs par défaut des seuils, utilisées si la configuration est absente ou invalide.
+const (
+	defaultFailureThreshold  = 3
+	defaultRecoveryThreshold = 2
+	defaultFlapWindow        = time.Hour
+	defaultFlapThreshold     = 4
+)
+
+// Thresholds définit quand un changement d'état est confirmé et quand un lien est jugé instable.
+type Thresholds struct {
+	Failure    int           // Échecs consécutifs avant de déclarer un lien INACCESSIBLE
+	Recovery   int           // Succès consécutifs avant de déclarer un lien de nouveau ACCESSIBLE
+	FlapWindow time.Duration // Fenêtre d'observation des changements d'état
+	FlapCount  int           // Changements d'état dans la fenêtre à partir desquels le lien est instable
+}
+
+// withDefaults remplace les valeurs invalides par les valeurs par défaut.
+func (t Thresholds) withDefaults() Thresholds {
+	if t.Failure <= 0 {
+		t.Failure = defaultFailureThreshold
+	}
+	if t.Recovery <= 0 {
+		t.Recovery = defaultRecoveryThreshold
+	}
+	if t.FlapWindow <= 0 {
+		t.FlapWindow = defaultFlapWindow
+	}
+	if t.FlapCount <= 1 {
+		t.FlapCount = defaultFlapThreshold
+	}
+	return t
+}
+
+// forLink applique les seuils propres au lien (colonnes nullables), s'ils sont définis.
+func (t Thresholds) forLink(link models.Link) Thresholds {
+	if link.FailureThreshold != nil && *link.FailureThreshold > 0 {
+		t.Failure = *link.FailureThreshold
+	}
+	if link.RecoveryThreshold != nil && *link.RecoveryThreshold > 0 {
+		t.Recovery = *link.RecoveryThreshold
+	}
+	return t
+}
+
+// linkState est l'état d'un lien suivi par le moniteur entre deux vérifications.
+type linkState struct {
+	longURL     string      // Destination vérifiée : l'état est réinitialisé si le lien change de destination
+	known       bool        // Faux tant qu'aucun état n'a été établi (ni en base, ni par une vérification)
+	up          bool        // État confirmé (après application des seuils)
+	failures    int         // Échecs consécutifs
+	successes   int         // Succès consécutifs
+	transitions []time.Time // Changements d'état confirmés dans la fenêtre d'instabilité
+	flapping    bool        // Lien instable : les notifications sont suspendues
+	notifiedUp  bool        // Dernier état notifié, pour prévenir à la fin de l'instabilité si besoin
+	tlsStatus   string      // Dernier état du certificat (voir models.TLS*), vide si aucun certificat examiné
+}
+
+// newLinkState initialise l'état d'un lien à partir de celui enregistré en base.
+func newLinkState(link models.Link) *linkState {
+	up, known := healthState(link.HealthStatus)
+	return &linkState{longURL: link.LongURL, known: known, up: up, notifiedUp: up, tlsStatus: link.TLSStatus}
+}
+
+// observeTLS enregistre l'état du certificat examiné lors d'une vérification (vide si aucun)
+// et indique s'il faut le notifier : lorsqu'il devient bientôt expiré ou invalide, puis lorsqu'il
+// redevient valide. Retourne aussi l'état précédent.
+func (s *linkState) observeTLS(status string) (previous string, notify bool) {
+	previous = s.tlsStatus
+	if status == "" || status == previous {
+		return previous, false
+	}
+	s.tlsStatus = status
+	return previous, status != models.TLSValid || previous != ""
+}
+
+// stateDecision décrit l'effet d'une vérification sur l'état d'un lien.
+type stateDecision struct {
+	Initial     bool // Premier état établi pour ce lien : pas de notification
+	Changed     bool // Changement d'état confirmé
+	Previous    bool // État avant la vérification
+	Current     bool // État après la vérification
+	Notify      bool // Le changement doit être notifié
+	FlapStarted bool // Le lien vient d'être jugé instable
+	FlapEnded   bool // Le lien vient de se stabiliser
+}
+
+// observe applique le résultat d'une vérification à l'état du lien.
+func (s *linkState) observe(healthy bool, now time.Time, t Thresholds) stateDecision {
+	if healthy {
+		s.successes++
+		s.failures = 0
+	} else {
+		s.failures++
+		s.successes = 0
+	}
+
+	d := stateDecision{Previous: s.up, Current: s.up}
+	if !s.known {
+		s.known, s.up, s.notifiedUp = true, healthy, healthy
+		d.Initial, d.Current = true, healthy
+		return d
+	}
+
+	switch {
+	case s.up && s.failures >= t.Failure:
+		s.up = false
+	case !s.up && s.successes >= t.Recovery:
+		s.up = true
+	}
+	d.Current = s.up
+	d.Changed = d.Current != d.Previous
+
+	// Ne garder que les changements d'état récents.
+	cutoff := now.Add(-t.FlapWindow)
+	kept := s.transitions[:0]
+	for _, at := range s.transitions {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	s.transitions = kept
+	if d.Changed {
+		s.transitions = append(s.transitions, now)
+	}
+
+	switch {
+	case !s.flapping && len(s.transitions) >= t.FlapCount:
+		s.flapping = true
+		d.FlapStarted = true
+	case s.flapping && len(s.transitions) == 0:
+		// Aucun changement d'état pendant toute la fenêtre : le lien est stable.
+		s.flapping = false
+		d.FlapEnded = true
+	}
+
+	switch {
+	case s.flapping:
+		// Instable : les changements sont enregistrés mais pas notifiés.
+	case d.FlapEnded:
+		// Prévenir seulement si l'état a changé depuis la dernière notification.
+		d.Notify = s.up != s.notifiedUp
+	default:
+		d.Notify = d.Changed
+	}
+	if d.Notify {
+		s.notifiedUp = s.up
+	}
+	return d
+}
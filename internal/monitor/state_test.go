@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// observation est une vérification appliquée à un linkState et la décision attendue.
+type observation struct {
+	healthy bool
+	at      time.Duration // Instant de la vérification, depuis le début du scénario
+
+	current     bool // État confirmé attendu après la vérification
+	notify      bool
+	flapStarted bool
+	flapEnded   bool
+}
+
+// Raccourcis pour la lisibilité des scénarios.
+const (
+	pass = true
+	fail = false
+	up   = true
+	down = false
+)
+
+func TestLinkStateObserve(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	thresholds := Thresholds{Failure: 3, Recovery: 2, FlapWindow: time.Hour, FlapCount: 4}
+	// Seuils à 1 : chaque vérification change l'état, pour tester la détection d'instabilité.
+	twitchy := Thresholds{Failure: 1, Recovery: 1, FlapWindow: time.Hour, FlapCount: 4}
+
+	tests := []struct {
+		name       string
+		health     string // État enregistré en base au démarrage
+		thresholds Thresholds
+		steps      []observation
+	}{
+		{
+			name: "N-1 échecs consécutifs ne suffisent pas", health: models.HealthUp, thresholds: thresholds,
+			steps: []observation{
+				{healthy: fail, at: 0, current: up},
+				{healthy: fail, at: time.Minute, current: up},
+			},
+		},
+		{
+			name: "N échecs consécutifs rendent le lien inaccessible", health: models.HealthUp, thresholds: thresholds,
+			steps: []observation{
+				{healthy: fail, at: 0, current: up},
+				{healthy: fail, at: time.Minute, current: up},
+				{healthy: fail, at: 2 * time.Minute, current: down, notify: true},
+				{healthy: fail, at: 3 * time.Minute, current: down},
+			},
+		},
+		{
+			name: "un succès remet à zéro la série d'échecs", health: models.HealthUp, thresholds: thresholds,
+			steps: []observation{
+				{healthy: fail, at: 0, current: up},
+				{healthy: fail, at: time.Minute, current: up},
+				{healthy: pass, at: 2 * time.Minute, current: up},
+				{healthy: fail, at: 3 * time.Minute, current: up},
+				{healthy: fail, at: 4 * time.Minute, current: up},
+				{healthy: fail, at: 5 * time.Minute, current: down, notify: true},
+			},
+		},
+		{
+			name: "série de succès avant le rétablissement", health: models.HealthDown, thresholds: thresholds,
+			steps: []observation{
+				{healthy: pass, at: 0, current: down},
+				{healthy: pass, at: time.Minute, current: up, notify: true},
+				{healthy: pass, at: 2 * time.Minute, current: up},
+			},
+		},
+		{
+			name: "un échec interrompt la série de succès", health: models.HealthDown, thresholds: thresholds,
+			steps: []observation{
+				{healthy: pass, at: 0, current: down},
+				{healthy: fail, at: time.Minute, current: down},
+				{healthy: pass, at: 2 * time.Minute, current: down},
+				{healthy: pass, at: 3 * time.Minute, current: up, notify: true},
+			},
+		},
+		{
+			name: "premier état établi sans notification", health: models.HealthUnknown, thresholds: thresholds,
+			steps: []observation{
+				{healthy: fail, at: 0, current: down},
+				{healthy: pass, at: time.Minute, current: down},
+				{healthy: pass, at: 2 * time.Minute, current: up, notify: true},
+			},
+		},
+		{
+			name: "instabilité dans la fenêtre : notifications suspendues", health: models.HealthUp, thresholds: twitchy,
+			steps: []observation{
+				{healthy: fail, at: 0, current: down, notify: true},
+				{healthy: pass, at: time.Minute, current: up, notify: true},
+				{healthy: fail, at: 2 * time.Minute, current: down, notify: true},
+				{healthy: pass, at: 3 * time.Minute, current: up, flapStarted: true},
+				{healthy: fail, at: 4 * time.Minute, current: down},
+				{healthy: pass, at: 5 * time.Minute, current: up},
+			},
+		},
+		{
+			name: "fin d'instabilité : notification si l'état a changé depuis la dernière", health: models.HealthUp, thresholds: twitchy,
+			steps: []observation{
+				{healthy: fail, at: 0, current: down, notify: true},
+				{healthy: pass, at: time.Minute, current: up, notify: true},
+				{healthy: fail, at: 2 * time.Minute, current: down, notify: true},
+				{healthy: pass, at: 3 * time.Minute, current: up, flapStarted: true},
+				// Aucun changement pendant toute la fenêtre : stable, à l'état "accessible" jamais notifié.
+				{healthy: pass, at: 3*time.Minute + time.Hour + time.Second, current: up, notify: true, flapEnded: true},
+			},
+		},
+		{
+			name: "fin d'instabilité sans notification si l'état notifié n'a pas changé", health: models.HealthUp, thresholds: twitchy,
+			steps: []observation{
+				{healthy: fail, at: 0, current: down, notify: true},
+				{healthy: pass, at: time.Minute, current: up, notify: true},
+				{healthy: fail, at: 2 * time.Minute, current: down, notify: true},
+				{healthy: pass, at: 3 * time.Minute, current: up, flapStarted: true},
+				{healthy: fail, at: 4 * time.Minute, current: down},
+				{healthy: fail, at: 4*time.Minute + time.Hour + time.Second, current: down, flapEnded: true},
+			},
+		},
+		{
+			name: "changements espacés au-delà de la fenêtre : pas d'instabilité", health: models.HealthUp, thresholds: twitchy,
+			steps: []observation{
+				{healthy: fail, at: 0, current: down, notify: true},
+				{healthy: pass, at: 40 * time.Minute, current: up, notify: true},
+				{healthy: fail, at: 80 * time.Minute, current: down, notify: true},
+				{healthy: pass, at: 120 * time.Minute, current: up, notify: true},
+				{healthy: fail, at: 160 * time.Minute, current: down, notify: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newLinkState(models.Link{HealthStatus: tt.health})
+			for i, step := range tt.steps {
+				d := state.observe(step.healthy, start.Add(step.at), tt.thresholds)
+				if d.Current != step.current || d.Notify != step.notify ||
+					d.FlapStarted != step.flapStarted || d.FlapEnded != step.flapEnded {
+					t.Fatalf("vérification %d : décision %+v, attendu current=%v notify=%v flapStarted=%v flapEnded=%v",
+						i+1, d, step.current, step.notify, step.flapStarted, step.flapEnded)
+				}
+			}
+		})
+	}
+}
+
+func TestThresholdsForLink(t *testing.T) {
+	global := Thresholds{}.withDefaults()
+	five, zero := 5, 0
+
+	got := global.forLink(models.Link{FailureThreshold: &five, RecoveryThreshold: &zero})
+	if got.Failure != 5 {
+		t.Errorf("Failure = %d, attendu 5 (seuil du lien)", got.Failure)
+	}
+	if got.Recovery != defaultRecoveryThreshold {
+		t.Errorf("Recovery = %d, attendu la valeur globale %d", got.Recovery, defaultRecoveryThreshold)
+	}
+	if got := global.forLink(models.Link{}); got != global {
+		t.Errorf("seuils sans valeur propre au lien = %+v, attendu %+v", got, global)
+	}
+}
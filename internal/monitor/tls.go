@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// defaultTLSExpiryWarning est la fenêtre d'alerte avant l'expiration d'un certificat si la configuration ne la précise pas.
+const defaultTLSExpiryWarning = 14 * 24 * time.Hour
+
+// certInfo décrit le certificat présenté par une destination HTTPS.
+type certInfo struct {
+	Subject       string
+	Issuer        string
+	NotAfter      time.Time
+	HostnameValid bool   // Le certificat couvre le nom d'hôte de l'URL
+	Error         string // Erreur de vérification (expiré, autorité inconnue, nom d'hôte...), vide si valide
+}
+
+// status retourne l'état du certificat (voir models.TLS*) à l'instant now.
+func (c *certInfo) status(now time.Time, warning time.Duration) string {
+	switch {
+	case c.Error != "" || !c.HostnameValid || !now.Before(c.NotAfter):
+		return models.TLSInvalid
+	case c.NotAfter.Sub(now) <= warning:
+		return models.TLSExpiring
+	default:
+		return models.TLSValid
+	}
+}
+
+// certFromState extrait le certificat d'une connexion TLS déjà vérifiée par le client HTTP.
+func certFromState(state *tls.ConnectionState) *certInfo {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	leaf := state.PeerCertificates[0]
+	return &certInfo{
+		Subject:       leaf.Subject.CommonName,
+		Issuer:        issuerName(leaf),
+		NotAfter:      leaf.NotAfter.UTC(),
+		HostnameValid: true, // La vérification du client HTTP inclut le nom d'hôte
+	}
+}
+
+// inspectCertificate récupère, sans le vérifier, le certificat présenté par l'hôte de rawURL,
+// puis le vérifie séparément pour décrire le problème. Utilisée lorsque la requête HTTP a échoué
+// à cause du certificat : le client HTTP ne donne alors accès ni à l'émetteur ni à l'expiration.
+func inspectCertificate(ctx context.Context, client *http.Client, rawURL string) *certInfo {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return nil
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: client.Timeout},
+		// La vérification est faite ci-dessous, pour enregistrer le certificat même s'il est invalide.
+		Config: &tls.Config{ServerName: host, InsecureSkipVerify: true},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	leaf := state.PeerCertificates[0]
+	info := &certInfo{
+		Subject:       leaf.Subject.CommonName,
+		Issuer:        issuerName(leaf),
+		NotAfter:      leaf.NotAfter.UTC(),
+		HostnameValid: leaf.VerifyHostname(host) == nil,
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Intermediates: intermediates}); err != nil {
+		info.Error = err.Error()
+	}
+	return info
+}
+
+// isCertificateError indique si err est due au certificat présenté par le serveur.
+func isCertificateError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	var hostnameErr x509.HostnameError
+	var authorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	return errors.As(err, &certErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &invalidErr)
+}
+
+// issuerName retourne un nom lisible pour l'émetteur d'un certificat.
+func issuerName(cert *x509.Certificate) string {
+	if cert.Issuer.CommonName != "" {
+		return cert.Issuer.CommonName
+	}
+	return cert.Issuer.String()
+}
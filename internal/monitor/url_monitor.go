@@ -1,107 +1,568 @@
 package monitor
 
 import (
+	"context"
+	"errors"
 	"log"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync" // Pour protéger l'accès concurrentiel à knownStates
+	"sync/atomic"
 	"time"
 
-	_ "github.com/axellelanca/urlshortener/internal/models"   // Importe les modèles de liens
+	"github.com/axellelanca/urlshortener/internal/models"     // Importe les modèles de liens
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le repository de liens
 )
 
+// monitorBatchSize est le nombre de liens chargés en mémoire à la fois pendant une vérification.
+const monitorBatchSize = 500
+
+// Valeurs par défaut du parallélisme, utilisées si la configuration est absente ou invalide.
+const (
+	defaultConcurrency        = 10
+	defaultPerHostConcurrency = 2
+)
+
+// ErrSweepInProgress est retournée par RunOnce lorsqu'une vérification est déjà en cours.
+var ErrSweepInProgress = errors.New("une vérification des URLs est déjà en cours")
+
+// Options regroupe les paramètres du moniteur d'URLs.
+type Options struct {
+	Interval           time.Duration // Intervalle entre chaque vérification (ex: 5 minutes)
+	Concurrency        int           // Nombre maximum de requêtes simultanées pendant une vérification
+	PerHostConcurrency int           // Nombre maximum de requêtes simultanées vers un même hôte
+	Jitter             time.Duration // Délai aléatoire maximum ajouté avant chaque vérification périodique
+	MaxRedirects       int           // Nombre maximum de redirections suivies lors d'une vérification
+	HealthyStatus      StatusSet     // Codes HTTP finaux considérés comme sains (2xx et 3xx si vide)
+	Notifiers          []Notifier    // Canaux prévenus des changements d'état (en plus des logs)
+	NotifyRetry        RetryPolicy   // Nouvelles tentatives des notifications en échec
+	Thresholds         Thresholds    // Seuils de confirmation des changements d'état et de détection d'instabilité
+	TLSExpiryWarning   time.Duration // Fenêtre avant l'expiration d'un certificat à partir de laquelle on prévient
+}
+
 // UrlMonitor gère la surveillance périodique des URLs longues.
 type UrlMonitor struct {
-	linkRepo    repository.LinkRepository // Pour récupérer les URLs à surveiller
-	interval    time.Duration             // Intervalle entre chaque vérification (ex: 5 minutes)
-	knownStates map[uint]bool             // État connu de chaque URL: map[LinkID]estAccessible (true/false)
-	mu          sync.Mutex                // Mutex pour protéger l'accès concurrentiel à knownStates
+	linkRepo    repository.LinkRepository      // Pour récupérer les URLs à surveiller
+	checkRepo   repository.LinkCheckRepository // Pour enregistrer l'historique des vérifications
+	opts        Options                        // Intervalle et parallélisme des vérifications
+	knownStates map[uint]*linkState            // État connu de chaque URL: map[LinkID]état (confirmé, compteurs, instabilité)
+	mu          sync.Mutex                     // Mutex pour protéger l'accès concurrentiel à knownStates
+	client      *http.Client                   // Client HTTP partagé par toutes les vérifications
+	sweeping    atomic.Bool                    // Vrai pendant une vérification, pour ne jamais en lancer deux à la fois
+	dispatcher  *Dispatcher                    // Envoie les changements d'état aux notifiers
+
+	lifecycleMu sync.Mutex         // Protège cancel et done
+	cancel      context.CancelFunc // Arrête la boucle lancée par Start (nil si elle ne tourne pas)
+	done        chan struct{}      // Fermé lorsque Start a rendu la main
 }
 
 // NewUrlMonitor crée et retourne une nouvelle instance de UrlMonitor.
 // Attention: retourne un pointeur
-func NewUrlMonitor(linkRepo repository.LinkRepository, interval time.Duration) *UrlMonitor {
+func NewUrlMonitor(linkRepo repository.LinkRepository, checkRepo repository.LinkCheckRepository, opts Options) *UrlMonitor {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.PerHostConcurrency <= 0 {
+		opts.PerHostConcurrency = defaultPerHostConcurrency
+	}
+	if opts.Jitter < 0 {
+		opts.Jitter = 0
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = defaultMaxRedirects
+	}
+	if opts.HealthyStatus.IsEmpty() {
+		opts.HealthyStatus = defaultHealthyStatus
+	}
+	opts.Thresholds = opts.Thresholds.withDefaults()
+	if opts.TLSExpiryWarning <= 0 {
+		opts.TLSExpiryWarning = defaultTLSExpiryWarning
+	}
+
 	return &UrlMonitor{
 		linkRepo:    linkRepo,
-		interval:    interval,
-		knownStates: make(map[uint]bool),
+		checkRepo:   checkRepo,
+		opts:        opts,
+		dispatcher:  NewDispatcher(opts.Notifiers, opts.NotifyRetry),
+		knownStates: make(map[uint]*linkState),
 		mu:          sync.Mutex{},
+		client:      newCheckClient(),
 	}
 }
 
-// Start lance la boucle de surveillance périodique des URLs.
-// Cette fonction est conçue pour être lancée dans une goroutine séparée.
-func (m *UrlMonitor) Start() {
-	log.Printf("[MONITOR] Démarrage du moniteur d'URLs avec un intervalle de %v...", m.interval)
-	ticker := time.NewTicker(m.interval) // Crée un ticker qui envoie un signal à chaque intervalle
-	defer ticker.Stop()                  // S'assure que le ticker est arrêté quand Start se termine
+// Start lance la boucle de surveillance périodique des URLs et bloque jusqu'à l'annulation
+// de ctx ou l'appel de Stop. Une vérification est lancée immédiatement, puis à chaque intervalle
+// (décalée d'un délai aléatoire pour ne pas solliciter les mêmes origines à heure fixe).
+// Si la vérification précédente n'est pas terminée, le tick est ignoré.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée ; un second appel
+// pendant que la boucle tourne retourne immédiatement.
+func (m *UrlMonitor) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m.lifecycleMu.Lock()
+	if m.cancel != nil {
+		m.lifecycleMu.Unlock()
+		log.Println("[MONITOR] Le moniteur est déjà démarré.")
+		return
+	}
+	done := make(chan struct{})
+	m.cancel, m.done = cancel, done
+	m.lifecycleMu.Unlock()
+
+	defer func() {
+		m.lifecycleMu.Lock()
+		m.cancel, m.done = nil, nil
+		m.lifecycleMu.Unlock()
+		close(done)
+	}()
+
+	log.Printf("[MONITOR] Démarrage du moniteur d'URLs avec un intervalle de %v (%d requête(s) simultanée(s), %d par hôte)...",
+		m.opts.Interval, m.opts.Concurrency, m.opts.PerHostConcurrency)
+	ticker := time.NewTicker(m.opts.Interval) // Crée un ticker qui envoie un signal à chaque intervalle
+	defer ticker.Stop()                       // S'assure que le ticker est arrêté quand Start se termine
+
+	// Les vérifications tournent dans leur propre goroutine pour que la boucle reste à l'écoute
+	// du ticker et de l'arrêt ; on attend la dernière avant de rendre la main.
+	var sweeps sync.WaitGroup
+	defer sweeps.Wait()
+	launch := func(delay time.Duration) {
+		sweeps.Add(1)
+		go func() {
+			defer sweeps.Done()
+			if !sleepCtx(ctx, delay) {
+				return
+			}
+			if err := m.RunOnce(ctx); errors.Is(err, ErrSweepInProgress) {
+				log.Println("[MONITOR] Vérification précédente toujours en cours, tick ignoré.")
+			}
+		}()
+	}
 
 	// Exécute une première vérification immédiatement au démarrage
-	m.checkUrls()
+	launch(0)
 
 	// Boucle principale du moniteur, déclenchée par le ticker
-	for range ticker.C {
-		m.checkUrls()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("[MONITOR] Moniteur d'URLs arrêté.")
+			return
+		case <-ticker.C:
+			if m.sweeping.Load() {
+				log.Println("[MONITOR] Vérification précédente toujours en cours, tick ignoré.")
+				continue
+			}
+			launch(m.jitter())
+		}
+	}
+}
+
+// jitter retourne un délai aléatoire dans [0, opts.Jitter).
+func (m *UrlMonitor) jitter() time.Duration {
+	if m.opts.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(m.opts.Jitter)))
+}
+
+// sleepCtx attend d pendant au plus la durée de vie de ctx. Retourne false si ctx a été annulé.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
 	}
 }
 
-// checkUrls effectue une vérification de l'état de toutes les URLs longues enregistrées.
-func (m *UrlMonitor) checkUrls() {
+// Stop arrête la boucle lancée par Start, annule les vérifications en cours
+// et attend que Start ait rendu la main. Sans effet si le moniteur ne tourne pas.
+func (m *UrlMonitor) Stop() {
+	m.lifecycleMu.Lock()
+	cancel, done := m.cancel, m.done
+	m.lifecycleMu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// RunOnce effectue une vérification de l'état de toutes les URLs longues enregistrées.
+// Elle peut être appelée à la demande, indépendamment de Start. Les liens sont vérifiés par
+// un pool de opts.Concurrency goroutines, avec au plus opts.PerHostConcurrency requêtes
+// simultanées vers un même hôte. L'annulation de ctx interrompt les requêtes en cours ;
+// l'erreur du contexte est alors retournée. Retourne ErrSweepInProgress si une vérification
+// est déjà en cours.
+func (m *UrlMonitor) RunOnce(ctx context.Context) error {
+	if !m.sweeping.CompareAndSwap(false, true) {
+		return ErrSweepInProgress
+	}
+	defer m.sweeping.Store(false)
+
 	log.Println("[MONITOR] Lancement de la vérification de l'état des URLs...")
+	start := time.Now()
+
+	// Les liens passent par un ordonnanceur qui ne confie à un worker que des liens dont l'hôte a
+	// une place libre : un worker n'attend jamais un hôte saturé pendant que d'autres hôtes patientent.
+	links := make(chan models.Link)
+	jobs := make(chan models.Link)
+	done := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < m.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				m.checkLink(ctx, link)
+				done <- hostOf(link.LongURL)
+			}
+		}()
+	}
 
-	// Récupérer toutes les URLs longues actives depuis le linkRepo (GetAllLinks).
-	links, err := m.linkRepo.GetAllLinks()
+	// Parcourir les liens par lots (GetLinksBatch) pour ne jamais charger toute la table en mémoire.
+	feedErr := make(chan error, 1)
+	go func() {
+		feedErr <- m.feedLinks(ctx, links)
+		close(links)
+	}()
+	newHostScheduler(m.opts.PerHostConcurrency, monitorBatchSize).run(ctx, links, jobs, done)
+	wg.Wait()
+	err := <-feedErr
+	// Attendre la fin des notifications, pour qu'une vérification terminée soit entièrement traitée.
+	m.dispatcher.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
 	if err != nil {
-		log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
+		if ctx.Err() != nil {
+			log.Printf("[MONITOR] Vérification interrompue : %v", err)
+		} else {
+			log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
+		}
+		return err
+	}
+	log.Printf("[MONITOR] Vérification de l'état des URLs terminée en %v.", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// feedLinks envoie dans jobs tous les liens non expirés, lot par lot, jusqu'à épuisement ou annulation de ctx.
+func (m *UrlMonitor) feedLinks(ctx context.Context, jobs chan<- models.Link) error {
+	var lastID uint
+	for {
+		links, err := m.linkRepo.GetLinksBatch(lastID, monitorBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		for _, link := range links {
+			// Inutile de surveiller un lien expiré : il ne redirige plus personne.
+			if link.IsExpired(now) {
+				continue
+			}
+			select {
+			case jobs <- link:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		lastID = links[len(links)-1].ID
+	}
+}
+
+// checkLink vérifie un lien, enregistre le résultat en base et notifie les changements d'état.
+// La place de son hôte a été réservée par l'ordonnanceur (voir hostScheduler).
+func (m *UrlMonitor) checkLink(ctx context.Context, link models.Link) {
+	// Vérifier son accessibilité (isUrlAccessible).
+	result := m.isUrlAccessible(ctx, link.LongURL)
+
+	// Une requête interrompue par l'arrêt ne dit rien de l'état de l'URL : on ne l'enregistre pas.
+	if ctx.Err() != nil {
 		return
 	}
 
-	for _, link := range links {
-		// Pour chaque lien, vérifier son accessibilité (isUrlAccessible).
-		currentState := m.isUrlAccessible(link.LongURL)
+	// Protéger l'accès à la map 'knownStates' car plusieurs goroutines vérifient des liens en parallèle
+	m.mu.Lock()
+	state, exists := m.knownStates[link.ID]
+	if !exists || state.longURL != link.LongURL {
+		// Premier passage depuis le démarrage, ou nouvelle destination : l'état de départ est celui enregistré en base.
+		state = newLinkState(link)
+		m.knownStates[link.ID] = state
+	}
+	decision := state.observe(result.Healthy, result.CheckedAt, m.opts.Thresholds.forLink(link))
+	tlsStatus := m.certificateStatus(result)
+	previousTLS, notifyTLS := state.observeTLS(tlsStatus)
+	m.mu.Unlock()
 
-		// Protéger l'accès à la map 'knownStates' car 'checkUrls' peut être exécuté concurremment
+	// L'état enregistré sur le lien est l'état confirmé, pas le résultat brut de la vérification.
+	if !m.recordCheck(link, result, decision.Current) {
+		// Le lien a changé de destination (ou a été supprimé) pendant la vérification : ce résultat
+		// ne dit rien de la nouvelle URL, et l'état suivi pour l'ancienne est oublié.
 		m.mu.Lock()
-		previousState, exists := m.knownStates[link.ID] // Récupère l'état précédent
-		m.knownStates[link.ID] = currentState           // Met à jour l'état actuel
+		if m.knownStates[link.ID] == state {
+			delete(m.knownStates, link.ID)
+		}
 		m.mu.Unlock()
+		return
+	}
+
+	// Le certificat est suivi indépendamment de l'accessibilité (et des seuils) : un certificat
+	// qui expire bientôt ne rend pas le lien inaccessible, mais mérite d'être signalé.
+	if notifyTLS {
+		m.notifyCertificate(ctx, link, result, previousTLS, tlsStatus)
+	}
+
+	// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier.
+	if decision.Initial {
+		log.Printf("[MONITOR] État initial pour le lien %s (%s) : %s",
+			link.ShortCode, link.LongURL, formatState(decision.Current))
+		return
+	}
+	if decision.FlapStarted {
+		log.Printf("[MONITOR] Le lien %s (%s) change d'état trop souvent : notifications suspendues.",
+			link.ShortCode, link.LongURL)
+	}
+	if decision.FlapEnded {
+		log.Printf("[MONITOR] Le lien %s (%s) est de nouveau stable (%s) : notifications rétablies.",
+			link.ShortCode, link.LongURL, formatState(decision.Current))
+	}
+	if decision.Changed && !decision.Notify {
+		log.Printf("[MONITOR] Le lien %s (%s) est passé de %s à %s (notification suspendue, lien instable).",
+			link.ShortCode, link.LongURL, formatState(decision.Previous), formatState(decision.Current))
+	}
+	if !decision.Notify {
+		return
+	}
+
+	// Si l'état a changé, le signaler dans les logs et aux notifiers configurés.
+	// En fin d'instabilité, l'état précédent est l'opposé de l'état actuel (dernier état notifié).
+	previousState := !decision.Current
+	log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !",
+		link.ShortCode, link.LongURL, formatState(previousState), formatState(decision.Current))
+	m.dispatcher.Dispatch(ctx, StateChange{
+		LinkID:      link.ID,
+		ShortCode:   link.ShortCode,
+		LongURL:     link.LongURL,
+		Previous:    healthStatus(previousState),
+		Current:     healthStatus(decision.Current),
+		StatusCode:  result.StatusCode,
+		FailureKind: result.FailureKind,
+		Error:       result.Error,
+		CheckedAt:   result.CheckedAt,
+	})
+}
+
+// certificateStatus retourne l'état du certificat examiné lors de la vérification (vide si aucun).
+func (m *UrlMonitor) certificateStatus(result checkResult) string {
+	if result.Certificate == nil {
+		return ""
+	}
+	return result.Certificate.status(result.CheckedAt, m.opts.TLSExpiryWarning)
+}
+
+// notifyCertificate signale dans les logs et aux notifiers un changement d'état du certificat d'un lien.
+func (m *UrlMonitor) notifyCertificate(ctx context.Context, link models.Link, result checkResult, previous, current string) {
+	cert := result.Certificate
+	change := StateChange{
+		Event:     EventCertificateChanged,
+		LinkID:    link.ID,
+		ShortCode: link.ShortCode,
+		LongURL:   link.LongURL,
+		Previous:  previous,
+		Current:   current,
+		Certificate: &CertificateInfo{
+			Subject:       cert.Subject,
+			Issuer:        cert.Issuer,
+			ExpiresAt:     cert.NotAfter,
+			DaysLeft:      int(cert.NotAfter.Sub(result.CheckedAt).Hours() / 24),
+			HostnameValid: cert.HostnameValid,
+			Error:         cert.Error,
+		},
+		CheckedAt: result.CheckedAt,
+	}
+	log.Printf("[NOTIFICATION] %s.", change.Summary())
+	m.dispatcher.Dispatch(ctx, change)
+}
+
+// hostScheduler répartit les liens à vérifier entre les workers en limitant le nombre de
+// vérifications simultanées par hôte. Un lien dont l'hôte est saturé est mis en attente (sans
+// occuper de worker) jusqu'à ce qu'une vérification de cet hôte se termine ; les liens des
+// autres hôtes continuent d'être distribués.
+type hostScheduler struct {
+	perHost   int                      // Vérifications simultanées maximum par hôte
+	maxQueued int                      // Liens en attente au-delà desquels on cesse de lire l'entrée
+	active    map[string]int           // Places réservées par hôte (liens distribués ou prêts)
+	pending   map[string][]models.Link // Liens en attente d'une place, par hôte
+	ready     []models.Link            // Liens dont la place est réservée, à confier au prochain worker libre
+	queued    int                      // Nombre total de liens dans pending
+}
+
+func newHostScheduler(perHost, maxQueued int) *hostScheduler {
+	return &hostScheduler{
+		perHost:   perHost,
+		maxQueued: maxQueued,
+		active:    make(map[string]int),
+		pending:   make(map[string][]models.Link),
+	}
+}
 
-		// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier.
-		if !exists {
-			log.Printf("[MONITOR] État initial pour le lien %s (%s) : %s",
-				link.ShortCode, link.LongURL, formatState(currentState))
-			continue
+// run lit les liens de links jusqu'à sa fermeture, les confie aux workers via jobs et reçoit
+// sur done l'hôte de chaque vérification terminée. Chaque lien envoyé sur jobs doit donner lieu
+// à exactement un envoi sur done. run ferme jobs et rend la main lorsque tous les liens ont été
+// traités. À l'annulation de ctx, les liens pas encore distribués sont abandonnés.
+func (h *hostScheduler) run(ctx context.Context, links <-chan models.Link, jobs chan<- models.Link, done <-chan string) {
+	defer close(jobs)
+
+	inFlight := 0
+	stopped := false
+	for {
+		if links == nil && len(h.ready) == 0 && h.queued == 0 && inFlight == 0 {
+			return
+		}
+		in := links
+		if len(h.ready)+h.queued >= h.maxQueued {
+			in = nil // Assez de liens en attente : l'entrée patiente
 		}
+		var out chan<- models.Link
+		var next models.Link
+		if len(h.ready) > 0 {
+			out, next = jobs, h.ready[0]
+		}
+		var cancelled <-chan struct{}
+		if !stopped {
+			cancelled = ctx.Done()
+		}
+
+		select {
+		case link, ok := <-in:
+			if !ok {
+				links = nil
+			} else if !stopped {
+				h.add(link)
+			}
+		case out <- next:
+			h.ready = h.ready[1:]
+			inFlight++
+		case host := <-done:
+			inFlight--
+			if !stopped {
+				h.release(host)
+			}
+		case <-cancelled:
+			// Abandonner les liens pas encore distribués ; on attend encore la fin des vérifications en cours.
+			stopped = true
+			h.ready, h.queued = nil, 0
+			clear(h.pending)
+		}
+	}
+}
+
+// add réserve une place pour l'hôte du lien, ou met le lien en attente si l'hôte est saturé.
+func (h *hostScheduler) add(link models.Link) {
+	host := hostOf(link.LongURL)
+	if h.active[host] < h.perHost {
+		h.active[host]++
+		h.ready = append(h.ready, link)
+		return
+	}
+	h.pending[host] = append(h.pending[host], link)
+	h.queued++
+}
 
-		// Comparer l'état actuel avec l'état précédent.
-		if currentState != previousState {
-			// Si l'état a changé, générer une fausse notification dans les logs.
-			log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !",
-				link.ShortCode, link.LongURL, formatState(previousState), formatState(currentState))
+// release libère la place d'une vérification terminée, en la transmettant au prochain lien en attente de cet hôte.
+func (h *hostScheduler) release(host string) {
+	if waiting := h.pending[host]; len(waiting) > 0 {
+		h.ready = append(h.ready, waiting[0])
+		h.queued--
+		if len(waiting) == 1 {
+			delete(h.pending, host)
+		} else {
+			h.pending[host] = waiting[1:]
 		}
+		return
+	}
+	if h.active[host]--; h.active[host] <= 0 {
+		delete(h.active, host)
 	}
-	log.Println("[MONITOR] Vérification de l'état des URLs terminée.")
 }
 
-// isUrlAccessible effectue une requête HTTP HEAD pour vérifier l'accessibilité d'une URL.
-func (m *UrlMonitor) isUrlAccessible(url string) bool {
-	// Définir un timeout pour éviter de bloquer trop longtemps (5 secondes c'est bien)
-	client := &http.Client{
-		Timeout: 5 * time.Second,
+// hostOf retourne l'hôte (en minuscules) d'une URL, ou l'URL elle-même si elle est invalide.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
 	}
+	return strings.ToLower(u.Hostname())
+}
 
-	// Effectuer une requête HEAD (plus légère que GET) sur l'URL.
-	resp, err := client.Head(url)
+// recordCheck enregistre le résultat d'une vérification dans l'historique du lien,
+// et son état confirmé (accessible) sur le lien. Un échec d'écriture est loggué sans interrompre la surveillance.
+// Retourne false si le lien n'a plus l'URL vérifiée pour destination : rien n'a alors été enregistré.
+func (m *UrlMonitor) recordCheck(link models.Link, result checkResult, accessible bool) bool {
+	check := &models.LinkCheck{
+		LinkID:        link.ID,
+		CheckedAt:     result.CheckedAt,
+		Healthy:       result.Healthy,
+		Method:        result.Method,
+		StatusCode:    result.StatusCode,
+		LatencyMs:     result.Latency.Milliseconds(),
+		FailureKind:   result.FailureKind,
+		Error:         models.Truncate(result.Error, 512),
+		FinalURL:      models.Truncate(result.FinalURL, 2048),
+		RedirectChain: encodeRedirectChain(result.Redirects),
+	}
+	if cert := result.Certificate; cert != nil {
+		expiresAt, hostnameValid := cert.NotAfter, cert.HostnameValid
+		check.TLSStatus = cert.status(result.CheckedAt, m.opts.TLSExpiryWarning)
+		check.TLSExpiresAt = &expiresAt
+		check.TLSIssuer = models.Truncate(cert.Issuer, 256)
+		check.TLSHostnameValid = &hostnameValid
+		check.TLSError = models.Truncate(cert.Error, 512)
+	}
+	recorded, err := m.checkRepo.RecordCheck(check, link.LongURL, healthStatus(accessible))
 	if err != nil {
-		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
-		return false
+		log.Printf("[MONITOR] ERREUR lors de l'enregistrement de la vérification du lien %s : %v", link.ShortCode, err)
+		return true
 	}
+	if !recorded {
+		log.Printf("[MONITOR] Le lien %s a changé de destination pendant sa vérification : résultat ignoré.", link.ShortCode)
+	}
+	return recorded
+}
 
-	// Assurez-vous de fermer le corps de la réponse pour libérer les ressources
-	defer resp.Body.Close()
+// healthStatus convertit un état du moniteur en état enregistré en base.
+func healthStatus(accessible bool) string {
+	if accessible {
+		return models.HealthUp
+	}
+	return models.HealthDown
+}
 
-	// Déterminer l'accessibilité basée sur le code de statut HTTP.
-	return resp.StatusCode >= 200 && resp.StatusCode < 400 // Codes 2xx ou 3xx
+// healthState convertit un état enregistré en base en état du moniteur.
+// Retourne false en second résultat si le lien n'a jamais été vérifié.
+func healthState(status string) (accessible bool, known bool) {
+	switch status {
+	case models.HealthUp:
+		return true, true
+	case models.HealthDown:
+		return false, true
+	default:
+		return false, false
+	}
 }
 
 // formatState est une fonction utilitaire pour rendre l'état plus lisible dans les logs.
@@ -0,0 +1,186 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// schedulerRun fait tourner un hostScheduler avec workers workers simulés. check est appelée
+// pour chaque lien distribué (elle peut bloquer pour simuler une vérification lente).
+// Retourne un channel fermé lorsque run a rendu la main.
+func schedulerRun(ctx context.Context, h *hostScheduler, workers int, links []models.Link, check func(models.Link)) <-chan struct{} {
+	in := make(chan models.Link)
+	jobs := make(chan models.Link)
+	done := make(chan string)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for link := range jobs {
+				check(link)
+				done <- hostOf(link.LongURL)
+			}
+		}()
+	}
+	go func() {
+		defer close(in)
+		for _, link := range links {
+			select {
+			case in <- link:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	finished := make(chan struct{})
+	go func() {
+		h.run(ctx, in, jobs, done)
+		close(finished)
+	}()
+	return finished
+}
+
+// linksFor crée n liens vers host.
+func linksFor(host string, n int) []models.Link {
+	links := make([]models.Link, n)
+	for i := range links {
+		links[i] = models.Link{ID: uint(i + 1), LongURL: "https://" + host + "/page"}
+	}
+	return links
+}
+
+// waitClosed attend la fermeture de ch, ou échoue au bout de 5 secondes.
+func waitClosed(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("délai dépassé : %s", what)
+	}
+}
+
+func TestHostSchedulerDoesNotStarveOtherHosts(t *testing.T) {
+	links := append(linksFor("slow.example", 5), linksFor("b.example", 1)...)
+	links = append(links, linksFor("c.example", 1)...)
+
+	var mu sync.Mutex
+	active := make(map[string]int)
+	maxActive := make(map[string]int)
+	checked := make(map[string]int)
+	unblock := make(chan struct{})
+	othersDone := make(chan struct{}, 2)
+
+	check := func(link models.Link) {
+		host := hostOf(link.LongURL)
+		mu.Lock()
+		active[host]++
+		maxActive[host] = max(maxActive[host], active[host])
+		mu.Unlock()
+
+		if host == "slow.example" {
+			<-unblock
+		}
+
+		mu.Lock()
+		active[host]--
+		checked[host]++
+		mu.Unlock()
+		if host != "slow.example" {
+			othersDone <- struct{}{}
+		}
+	}
+
+	// Trois workers, une seule requête à la fois par hôte : les liens de slow.example ne doivent
+	// pas occuper les workers libres pendant que b.example et c.example attendent.
+	finished := schedulerRun(context.Background(), newHostScheduler(1, 100), 3, links, check)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-othersDone:
+		case <-time.After(5 * time.Second):
+			close(unblock)
+			t.Fatal("les autres hôtes n'ont pas été vérifiés pendant que slow.example était saturé")
+		}
+	}
+	close(unblock)
+	waitClosed(t, finished, "fin de l'ordonnanceur")
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]int{"slow.example": 5, "b.example": 1, "c.example": 1}
+	for host, n := range want {
+		if checked[host] != n {
+			t.Errorf("%s : %d vérification(s), attendu %d", host, checked[host], n)
+		}
+		if maxActive[host] > 1 {
+			t.Errorf("%s : %d vérifications simultanées, maximum 1", host, maxActive[host])
+		}
+	}
+}
+
+func TestHostSchedulerRespectsPerHostLimit(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive, checked := 0, 0, 0
+	check := func(models.Link) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		active--
+		checked++
+		mu.Unlock()
+	}
+
+	// maxQueued inférieur au nombre de liens : l'entrée est lue au rythme des vérifications.
+	finished := schedulerRun(context.Background(), newHostScheduler(2, 4), 8, linksFor("one.example", 20), check)
+	waitClosed(t, finished, "fin de l'ordonnanceur")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if checked != 20 {
+		t.Errorf("%d vérification(s), attendu 20", checked)
+	}
+	if maxActive > 2 {
+		t.Errorf("%d vérifications simultanées vers le même hôte, maximum 2", maxActive)
+	}
+}
+
+func TestHostSchedulerDropsPendingLinksOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	checked := 0
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	check := func(models.Link) {
+		mu.Lock()
+		checked++
+		first := checked == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-unblock
+		}
+	}
+
+	finished := schedulerRun(ctx, newHostScheduler(1, 100), 2, linksFor("one.example", 10), check)
+	waitClosed(t, started, "première vérification")
+	cancel()
+	// Laisser l'ordonnanceur constater l'annulation avant la fin de la vérification en cours.
+	time.Sleep(10 * time.Millisecond)
+	close(unblock)
+	waitClosed(t, finished, "fin de l'ordonnanceur après annulation")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if checked != 1 {
+		t.Errorf("%d vérification(s), attendu 1 : les liens en attente doivent être abandonnés", checked)
+	}
+}
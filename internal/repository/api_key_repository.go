@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository définit les méthodes d'accès aux clés d'API (table 'api_keys').
+type APIKeyRepository interface {
+	// CreateAPIKey insère une nouvelle clé
+	CreateAPIKey(key *models.APIKey) error
+
+	// GetAPIKeyByHash récupère une clé par l'empreinte de sa valeur
+	// Retourne gorm.ErrRecordNotFound si non trouvée
+	GetAPIKeyByHash(keyHash string) (*models.APIKey, error)
+
+	// ListAPIKeys retourne toutes les clés, révoquées comprises, de la plus ancienne à la plus récente
+	ListAPIKeys() ([]models.APIKey, error)
+
+	// RevokeAPIKey révoque la clé d'ID donné
+	// Retourne gorm.ErrRecordNotFound si aucune clé active ne porte cet ID
+	RevokeAPIKey(id uint, at time.Time) error
+
+	// TouchAPIKey enregistre la date de dernière utilisation d'une clé
+	TouchAPIKey(id uint, at time.Time) error
+}
+
+// GormAPIKeyRepository est l'implémentation de APIKeyRepository utilisant GORM.
+type GormAPIKeyRepository struct {
+	db *gorm.DB // Connexion à la base de données GORM
+}
+
+// NewAPIKeyRepository crée et retourne une nouvelle instance de GormAPIKeyRepository.
+func NewAPIKeyRepository(db *gorm.DB) *GormAPIKeyRepository {
+	return &GormAPIKeyRepository{db: db}
+}
+
+// CreateAPIKey insère une nouvelle clé dans la base de données.
+func (r *GormAPIKeyRepository) CreateAPIKey(key *models.APIKey) error {
+	if err := r.db.Create(key).Error; err != nil {
+		return fmt.Errorf("erreur lors de la création de la clé d'API '%s' : %w", key.Name, err)
+	}
+	return nil
+}
+
+// GetAPIKeyByHash récupère une clé par l'empreinte de sa valeur.
+func (r *GormAPIKeyRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération de la clé d'API : %w", err)
+	}
+	return &key, nil
+}
+
+// ListAPIKeys retourne toutes les clés, triées par ID.
+func (r *GormAPIKeyRepository) ListAPIKeys() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.Order("id").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des clés d'API : %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey révoque la clé d'ID donné. La ligne est conservée pour garder la trace de la clé.
+func (r *GormAPIKeyRepository) RevokeAPIKey(id uint, at time.Time) error {
+	result := r.db.Model(&models.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at.UTC())
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la révocation de la clé d'API %d : %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("erreur lors de la révocation de la clé d'API %d : %w", id, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// TouchAPIKey enregistre la date de dernière utilisation d'une clé.
+func (r *GormAPIKeyRepository) TouchAPIKey(id uint, at time.Time) error {
+	// UpdateColumn : pas de hook ni de mise à jour des autres champs
+	result := r.db.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at.UTC())
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la mise à jour de la clé d'API %d : %w", id, result.Error)
+	}
+	return nil
+}
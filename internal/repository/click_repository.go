@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
@@ -17,10 +18,104 @@ import (
 type ClickRepository interface {
 	// CreateClick insère un nouvel événement de clic dans la base de données
 	CreateClick(click *models.Click) error
+
+	// CreateClicks insère un lot de clics en une seule transaction et met à jour links.click_count
+	// Utilisé par les workers, qui regroupent les événements pour soulager SQLite
+	CreateClicks(clicks []*models.Click) error
 	
 	// CountClicksByLinkID compte le nombre de clics pour un lien spécifique
 	// Utilisé par LinkService pour les stats
 	CountClicksByLinkID(linkID uint) (int, error)
+
+	// CountClicksByBucket compte les clics d'un lien regroupés par tranche de temps (heure, jour ou semaine)
+	// Utilisé par ClickService pour les séries temporelles
+	CountClicksByBucket(filter ClickFilter, granularity string) ([]TimeBucket, error)
+
+	// CountClicksByDimension compte les clics d'un lien regroupés par valeur d'une dimension (browser, os, device)
+	// Utilisé par ClickService pour les répartitions
+	CountClicksByDimension(filter ClickFilter, dimension string) ([]DimensionCount, error)
+
+	// CountClicks compte les clics correspondant au filtre
+	CountClicks(filter ClickFilter) (int64, error)
+
+	// CountTopReferrers retourne les limit sites référents ayant généré le plus de clics
+	CountTopReferrers(filter ClickFilter, limit int) ([]DimensionCount, error)
+}
+
+// Dimensions acceptées par CountClicksByDimension.
+const (
+	DimensionBrowser = "browser"
+	DimensionOS      = "os"
+	DimensionDevice  = "device"
+)
+
+// dimensionColumns associe chaque dimension exposée à sa colonne de la table 'clicks'.
+// Cette liste blanche évite toute injection SQL via le nom de la dimension.
+var dimensionColumns = map[string]string{
+	DimensionBrowser: "browser",
+	DimensionOS:      "os",
+	DimensionDevice:  "device",
+}
+
+// IsValidDimension indique si une dimension peut être passée à CountClicksByDimension.
+func IsValidDimension(dimension string) bool {
+	_, ok := dimensionColumns[dimension]
+	return ok
+}
+
+// DimensionCount est le nombre de clics pour une valeur d'une dimension.
+type DimensionCount struct {
+	Value string // Valeur de la dimension (ex: "Chrome")
+	Count int64  // Nombre de clics
+}
+
+// Granularités acceptées par CountClicksByBucket.
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+	GranularityWeek = "week"
+)
+
+// BucketLayout est le format (UTC) du début de tranche retourné dans TimeBucket.Bucket.
+const BucketLayout = "2006-01-02 15:04:05"
+
+// bucketExprs associe chaque granularité à l'expression SQLite qui calcule le début de la tranche.
+// Pour la semaine, on recule de 6 jours puis on avance au lundi suivant : on obtient ainsi
+// le lundi de la semaine du clic (semaines ISO, du lundi au dimanche).
+var bucketExprs = map[string]string{
+	GranularityHour: "strftime('%Y-%m-%d %H:00:00', timestamp)",
+	GranularityDay:  "strftime('%Y-%m-%d 00:00:00', timestamp)",
+	GranularityWeek: "strftime('%Y-%m-%d 00:00:00', timestamp, '-6 days', 'weekday 1')",
+}
+
+// ClickFilter restreint les requêtes d'agrégation aux clics d'un lien sur une période.
+// Une borne nulle (time.Time{}) n'est pas appliquée. Les clics de robots sont exclus sauf si IncludeBots.
+type ClickFilter struct {
+	LinkID      uint      // Lien concerné
+	From        time.Time // Début de période (inclus)
+	To          time.Time // Fin de période (exclue)
+	IncludeBots bool      // Inclure les clics marqués is_bot
+}
+
+// apply ajoute les conditions du filtre à une requête sur la table 'clicks'.
+func (f ClickFilter) apply(query *gorm.DB) *gorm.DB {
+	query = query.Where("link_id = ?", f.LinkID)
+	if !f.IncludeBots {
+		query = query.Where("is_bot = ?", false)
+	}
+	if !f.From.IsZero() {
+		query = query.Where("timestamp >= ?", f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		query = query.Where("timestamp < ?", f.To.UTC())
+	}
+	return query
+}
+
+// TimeBucket est le nombre de clics d'une tranche de temps.
+type TimeBucket struct {
+	Bucket string // Début de la tranche, au format BucketLayout (UTC)
+	Count  int64  // Nombre de clics dans la tranche
 }
 
 // GormClickRepository est l'implémentation de l'interface ClickRepository utilisant GORM.
@@ -39,11 +134,47 @@ func NewClickRepository(db *gorm.DB) *GormClickRepository {
 //
 // Cette méthode est appelée par les workers de clics de manière asynchrone.
 func (r *GormClickRepository) CreateClick(click *models.Click) error {
-	// db.Create() génère : INSERT INTO clicks (link_id, timestamp, user_agent, ip_address) VALUES (?, ?, ?, ?)
+	// Même chemin qu'un lot : le compteur de clics du lien est mis à jour dans la même transaction.
 	// GORM va automatiquement remplir click.ID avec l'ID auto-incrémenté
-	result := r.db.Create(click)
-	if result.Error != nil {
-		return fmt.Errorf("erreur lors de la création du clic : %w", result.Error)
+	return r.CreateClicks([]*models.Click{click})
+}
+
+// maxRowsPerInsert borne le nombre de lignes d'une instruction INSERT multi-lignes,
+// pour rester sous la limite de variables liées de SQLite quelle que soit la taille du lot.
+const maxRowsPerInsert = 500
+
+// CreateClicks insère un lot de clics dans une seule transaction, via des INSERT multi-lignes,
+// et incrémente le nombre de clics des liens concernés.
+// Soit tous les clics du lot sont enregistrés (et comptés), soit aucun.
+func (r *GormClickRepository) CreateClicks(clicks []*models.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+	// db.Transaction() ouvre une transaction, la valide si la fonction retourne nil et l'annule sinon.
+	// CreateInBatches génère : INSERT INTO clicks (...) VALUES (...), (...), ... par tranches de maxRowsPerInsert
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(clicks, maxRowsPerInsert).Error; err != nil {
+			return err
+		}
+		// Les compteurs dénormalisés des liens (links.click_count) suivent les clics hors robots du lot.
+		counts := make(map[uint]int64)
+		for _, click := range clicks {
+			if !click.IsBot {
+				counts[click.LinkID]++
+			}
+		}
+		for linkID, count := range counts {
+			// UpdateColumn : pas de hook ; Unscoped : un lien supprimé garde un compteur juste
+			err := tx.Unscoped().Model(&models.Link{}).Where("id = ?", linkID).
+				UpdateColumn("click_count", gorm.Expr("click_count + ?", count)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("erreur lors de la création d'un lot de %d clics : %w", len(clicks), err)
 	}
 	return nil
 }
@@ -61,3 +192,75 @@ func (r *GormClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	}
 	return int(count), nil // Convert the int64 count to an int
 }
+
+// CountClicksByBucket compte les clics d'un lien sur [filter.From, filter.To[, regroupés par tranche.
+// Seules les tranches contenant au moins un clic sont retournées, triées chronologiquement.
+func (r *GormClickRepository) CountClicksByBucket(filter ClickFilter, granularity string) ([]TimeBucket, error) {
+	bucketExpr, ok := bucketExprs[granularity]
+	if !ok {
+		return nil, fmt.Errorf("granularité inconnue : %s", granularity)
+	}
+
+	var buckets []TimeBucket
+	// Génère : SELECT <bucket> AS bucket, COUNT(*) AS count FROM clicks
+	//          WHERE link_id = ? AND timestamp >= ? AND timestamp < ? GROUP BY bucket ORDER BY bucket
+	result := filter.apply(r.db.Model(&models.Click{})).
+		Select(bucketExpr + " AS bucket, COUNT(*) AS count").
+		Group("bucket").
+		Order("bucket").
+		Scan(&buckets)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erreur lors de l'agrégation des clics pour LinkID %d : %w", filter.LinkID, result.Error)
+	}
+	return buckets, nil
+}
+
+// CountClicksByDimension compte les clics d'un lien regroupés par valeur de la dimension,
+// du plus fréquent au moins fréquent.
+func (r *GormClickRepository) CountClicksByDimension(filter ClickFilter, dimension string) ([]DimensionCount, error) {
+	column, ok := dimensionColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("dimension inconnue : %s", dimension)
+	}
+
+	var counts []DimensionCount
+	// Génère : SELECT <colonne> AS value, COUNT(*) AS count FROM clicks
+	//          WHERE link_id = ? ... GROUP BY value ORDER BY count DESC, value
+	result := filter.apply(r.db.Model(&models.Click{})).
+		Select(column + " AS value, COUNT(*) AS count").
+		Group("value").
+		Order("count DESC, value").
+		Scan(&counts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erreur lors de la répartition des clics par %s pour LinkID %d : %w", dimension, filter.LinkID, result.Error)
+	}
+	return counts, nil
+}
+
+// CountClicks compte les clics correspondant au filtre (lien et période).
+func (r *GormClickRepository) CountClicks(filter ClickFilter) (int64, error) {
+	var count int64
+	result := filter.apply(r.db.Model(&models.Click{})).Count(&count)
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors du comptage des clics pour LinkID %d : %w", filter.LinkID, result.Error)
+	}
+	return count, nil
+}
+
+// CountTopReferrers retourne les limit sites référents ayant généré le plus de clics.
+// Les accès directs (referrer vide) sont comptés comme une valeur à part entière.
+func (r *GormClickRepository) CountTopReferrers(filter ClickFilter, limit int) ([]DimensionCount, error) {
+	var counts []DimensionCount
+	// Génère : SELECT referrer AS value, COUNT(*) AS count FROM clicks WHERE link_id = ? ...
+	//          GROUP BY value ORDER BY count DESC, value LIMIT ?
+	result := filter.apply(r.db.Model(&models.Click{})).
+		Select("referrer AS value, COUNT(*) AS count").
+		Group("value").
+		Order("count DESC, value").
+		Limit(limit).
+		Scan(&counts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erreur lors du classement des référents pour LinkID %d : %w", filter.LinkID, result.Error)
+	}
+	return counts, nil
+}
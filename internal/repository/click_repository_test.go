@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// createClicksAt crée un lien et un clic hors robots à chacun des instants donnés.
+func createClicksAt(t *testing.T, repo *GormClickRepository, times []time.Time) uint {
+	t.Helper()
+	link := &models.Link{ShortCode: "bucket", LongURL: "https://example.com", CreatedAt: time.Now().UTC()}
+	if err := repo.db.Create(link).Error; err != nil {
+		t.Fatalf("création du lien: %v", err)
+	}
+	clicks := make([]*models.Click, len(times))
+	for i, ts := range times {
+		clicks[i] = &models.Click{LinkID: link.ID, Timestamp: ts}
+	}
+	if err := repo.CreateClicks(clicks); err != nil {
+		t.Fatalf("CreateClicks: %v", err)
+	}
+	return link.ID
+}
+
+// formatBuckets rend des tranches lisibles dans les messages d'échec.
+func formatBuckets(buckets []TimeBucket) string {
+	s := ""
+	for _, b := range buckets {
+		s += fmt.Sprintf("[%s: %d] ", b.Bucket, b.Count)
+	}
+	return s
+}
+
+func TestCountClicksByBucketEdges(t *testing.T) {
+	utc := func(s string) time.Time {
+		ts, err := time.Parse("2006-01-02 15:04:05.999999999", s)
+		if err != nil {
+			t.Fatalf("date %q: %v", s, err)
+		}
+		return ts
+	}
+	paris := time.FixedZone("UTC+2", 2*3600)
+
+	tests := []struct {
+		name        string
+		granularity string
+		clicks      []time.Time
+		want        []TimeBucket
+	}{
+		{
+			name:        "heure : dernière nanoseconde et début de l'heure suivante",
+			granularity: GranularityHour,
+			clicks:      []time.Time{utc("2025-06-01 12:00:00"), utc("2025-06-01 12:59:59.999999999"), utc("2025-06-01 13:00:00")},
+			want:        []TimeBucket{{"2025-06-01 12:00:00", 2}, {"2025-06-01 13:00:00", 1}},
+		},
+		{
+			name:        "jour : minuit UTC",
+			granularity: GranularityDay,
+			clicks:      []time.Time{utc("2025-06-01 00:00:00"), utc("2025-06-01 23:59:59.999"), utc("2025-06-02 00:00:00")},
+			want:        []TimeBucket{{"2025-06-01 00:00:00", 2}, {"2025-06-02 00:00:00", 1}},
+		},
+		{
+			// Le 1er juin 2025 est un dimanche : les semaines commencent le lundi 26 mai, 2 juin et 9 juin.
+			name:        "semaine : du lundi 00:00 au dimanche 23:59",
+			granularity: GranularityWeek,
+			clicks: []time.Time{
+				utc("2025-06-01 23:59:59"), // Dimanche : semaine précédente
+				utc("2025-06-02 00:00:00"), // Lundi
+				utc("2025-06-04 12:00:00"), // Mercredi
+				utc("2025-06-08 23:59:59"), // Dimanche
+				utc("2025-06-09 00:00:00"), // Lundi suivant
+			},
+			want: []TimeBucket{{"2025-05-26 00:00:00", 1}, {"2025-06-02 00:00:00", 3}, {"2025-06-09 00:00:00", 1}},
+		},
+		{
+			name:        "semaine : changement de mois et d'année",
+			granularity: GranularityWeek,
+			clicks:      []time.Time{utc("2024-12-31 10:00:00"), utc("2025-01-05 23:00:00"), utc("2025-01-06 00:00:00")},
+			want:        []TimeBucket{{"2024-12-30 00:00:00", 2}, {"2025-01-06 00:00:00", 1}},
+		},
+		{
+			// 01:30 à UTC+2 est 23:30 UTC la veille : les tranches sont toujours calculées en UTC.
+			name:        "horodatage hors UTC : tranche du jour UTC",
+			granularity: GranularityDay,
+			clicks:      []time.Time{time.Date(2025, 6, 2, 1, 30, 0, 0, paris), utc("2025-06-02 00:30:00")},
+			want:        []TimeBucket{{"2025-06-01 00:00:00", 1}, {"2025-06-02 00:00:00", 1}},
+		},
+		{
+			name:        "horodatage hors UTC : tranche de l'heure UTC",
+			granularity: GranularityHour,
+			clicks:      []time.Time{time.Date(2025, 6, 2, 1, 30, 0, 0, paris)},
+			want:        []TimeBucket{{"2025-06-01 23:00:00", 1}},
+		},
+		{
+			name:        "horodatage hors UTC : lundi local, dimanche UTC",
+			granularity: GranularityWeek,
+			clicks:      []time.Time{time.Date(2025, 6, 2, 1, 0, 0, 0, paris)},
+			want:        []TimeBucket{{"2025-05-26 00:00:00", 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewClickRepository(newTestDB(t))
+			linkID := createClicksAt(t, repo, tt.clicks)
+
+			got, err := repo.CountClicksByBucket(ClickFilter{LinkID: linkID}, tt.granularity)
+			if err != nil {
+				t.Fatalf("CountClicksByBucket: %v", err)
+			}
+			if formatBuckets(got) != formatBuckets(tt.want) {
+				t.Errorf("tranches = %s\nattendu   %s", formatBuckets(got), formatBuckets(tt.want))
+			}
+		})
+	}
+}
+
+func TestCountClicksByBucketFilter(t *testing.T) {
+	repo := NewClickRepository(newTestDB(t))
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)
+	linkID := createClicksAt(t, repo, []time.Time{
+		from.Add(-time.Nanosecond), // Avant la période
+		from,                       // Borne de début incluse
+		to.Add(-time.Second),
+		to, // Borne de fin exclue
+	})
+	bot := &models.Click{LinkID: linkID, Timestamp: from.Add(time.Hour), IsBot: true}
+	if err := repo.CreateClicks([]*models.Click{bot}); err != nil {
+		t.Fatalf("CreateClicks: %v", err)
+	}
+
+	// La borne de début est exprimée dans un autre fuseau : elle est ramenée en UTC.
+	filter := ClickFilter{LinkID: linkID, From: from.In(time.FixedZone("UTC-5", -5*3600)), To: to}
+	got, err := repo.CountClicksByBucket(filter, GranularityDay)
+	if err != nil {
+		t.Fatalf("CountClicksByBucket: %v", err)
+	}
+	want := []TimeBucket{{"2025-06-01 00:00:00", 1}, {"2025-06-02 00:00:00", 1}}
+	if formatBuckets(got) != formatBuckets(want) {
+		t.Errorf("tranches = %s\nattendu   %s", formatBuckets(got), formatBuckets(want))
+	}
+
+	filter.IncludeBots = true
+	got, err = repo.CountClicksByBucket(filter, GranularityDay)
+	if err != nil {
+		t.Fatalf("CountClicksByBucket: %v", err)
+	}
+	want = []TimeBucket{{"2025-06-01 00:00:00", 2}, {"2025-06-02 00:00:00", 1}}
+	if formatBuckets(got) != formatBuckets(want) {
+		t.Errorf("avec les robots : tranches = %s\nattendu   %s", formatBuckets(got), formatBuckets(want))
+	}
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB ouvre une base SQLite en mémoire, migrée avec tous les modèles.
+// Une base ":memory:" est propre à chaque connexion : le pool est limité à une seule.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("ouverture de la base: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("base SQL sous-jacente: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&models.Link{}, &models.Click{}, &models.LinkCheck{}, &models.APIKey{}, &models.User{}); err != nil {
+		t.Fatalf("migration: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// LinkCheckRepository définit les méthodes d'accès à l'historique des vérifications
+// effectuées par le moniteur d'URLs (table 'link_checks').
+type LinkCheckRepository interface {
+	// RecordCheck enregistre une vérification et met à jour l'état courant du lien
+	// (colonnes health_status, last_checked_at, final_url et, si un certificat a été
+	// examiné, tls_status, tls_expires_at, tls_issuer et tls_hostname_valid) dans une même transaction
+	// Retourne false, sans rien enregistrer, si le lien n'a plus longURL pour destination (ou a été supprimé)
+	RecordCheck(check *models.LinkCheck, longURL, status string) (bool, error)
+
+	// ListChecks retourne les vérifications d'un lien, de la plus récente à la plus ancienne
+	ListChecks(filter LinkCheckFilter) ([]models.LinkCheck, error)
+
+	// SummarizeChecks compte les vérifications d'un lien et celles en échec
+	SummarizeChecks(filter LinkCheckFilter) (*LinkCheckSummary, error)
+}
+
+// LinkCheckFilter restreint les vérifications prises en compte.
+// Une borne à zéro (time.Time{}) n'est pas appliquée.
+type LinkCheckFilter struct {
+	LinkID uint
+	From   time.Time // Borne inférieure incluse
+	To     time.Time // Borne supérieure exclue
+	Limit  int       // Nombre maximum de vérifications retournées par ListChecks (0 = pas de limite)
+}
+
+// apply ajoute les conditions du filtre à une requête sur la table 'link_checks'.
+func (f LinkCheckFilter) apply(query *gorm.DB) *gorm.DB {
+	query = query.Where("link_id = ?", f.LinkID)
+	if !f.From.IsZero() {
+		query = query.Where("checked_at >= ?", f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		query = query.Where("checked_at < ?", f.To.UTC())
+	}
+	return query
+}
+
+// LinkCheckSummary résume les vérifications d'un lien sur une période.
+type LinkCheckSummary struct {
+	Total    int64 // Nombre de vérifications
+	Failures int64 // Nombre de vérifications en échec
+}
+
+// GormLinkCheckRepository est l'implémentation de LinkCheckRepository utilisant GORM.
+type GormLinkCheckRepository struct {
+	db *gorm.DB // Connexion à la base de données GORM
+}
+
+// NewLinkCheckRepository crée et retourne une nouvelle instance de GormLinkCheckRepository.
+func NewLinkCheckRepository(db *gorm.DB) *GormLinkCheckRepository {
+	return &GormLinkCheckRepository{db: db}
+}
+
+// RecordCheck insère la vérification et reporte son résultat sur le lien, dans une transaction :
+// l'historique et l'état courant ne peuvent pas diverger. longURL est l'URL vérifiée : si le lien
+// a changé de destination pendant la vérification, le résultat ne le concerne plus et rien n'est
+// enregistré (sans quoi l'état de l'ancienne URL écraserait celui remis à zéro par UpdateLink).
+func (r *GormLinkCheckRepository) RecordCheck(check *models.LinkCheck, longURL, status string) (bool, error) {
+	recorded := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// UpdateColumns : ne touche qu'aux colonnes de santé, sans hooks ni mise à jour des autres champs
+		columns := map[string]interface{}{
+			"health_status":   status,
+			"last_checked_at": check.CheckedAt,
+			"final_url":       check.FinalURL,
+		}
+		// Sans certificat examiné (destination injoignable...), on conserve le dernier état TLS connu.
+		if check.TLSStatus != "" {
+			columns["tls_status"] = check.TLSStatus
+			columns["tls_expires_at"] = check.TLSExpiresAt
+			columns["tls_issuer"] = check.TLSIssuer
+			columns["tls_hostname_valid"] = check.TLSHostnameValid
+		}
+		result := tx.Model(&models.Link{}).Where("id = ? AND long_url = ?", check.LinkID, longURL).UpdateColumns(columns)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(check).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("erreur lors de l'enregistrement de la vérification du lien %d : %w", check.LinkID, err)
+	}
+	return recorded, nil
+}
+
+// ListChecks retourne les vérifications d'un lien, de la plus récente à la plus ancienne.
+// SQL généré : SELECT * FROM link_checks WHERE link_id = ? [AND checked_at ...] ORDER BY checked_at DESC, id DESC LIMIT ?
+func (r *GormLinkCheckRepository) ListChecks(filter LinkCheckFilter) ([]models.LinkCheck, error) {
+	var checks []models.LinkCheck
+	query := filter.apply(r.db.Model(&models.LinkCheck{})).Order("checked_at DESC, id DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Find(&checks).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des vérifications du lien %d : %w", filter.LinkID, err)
+	}
+	return checks, nil
+}
+
+// SummarizeChecks compte les vérifications d'un lien sur la période du filtre (Limit est ignoré).
+// SQL généré : SELECT COUNT(*), SUM(CASE WHEN healthy THEN 0 ELSE 1 END) FROM link_checks WHERE ...
+func (r *GormLinkCheckRepository) SummarizeChecks(filter LinkCheckFilter) (*LinkCheckSummary, error) {
+	var row struct {
+		Total    int64
+		Failures int64
+	}
+	err := filter.apply(r.db.Model(&models.LinkCheck{})).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN healthy THEN 0 ELSE 1 END), 0) AS failures").
+		Scan(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du résumé des vérifications du lien %d : %w", filter.LinkID, err)
+	}
+	return &LinkCheckSummary{Total: row.Total, Failures: row.Failures}, nil
+}
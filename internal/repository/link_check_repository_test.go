@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+func TestRecordCheckSkipsChangedDestination(t *testing.T) {
+	db := newTestDB(t)
+	linkRepo := NewLinkRepository(db)
+	checkRepo := NewLinkCheckRepository(db)
+
+	link := &models.Link{ShortCode: "abc", LongURL: "https://old.example.com", CreatedAt: time.Now().UTC()}
+	if err := linkRepo.CreateLink(link); err != nil {
+		t.Fatalf("création du lien: %v", err)
+	}
+
+	// La destination change pendant une vérification de l'ancienne URL.
+	link.LongURL = "https://new.example.com"
+	if err := linkRepo.UpdateLink(link, true); err != nil {
+		t.Fatalf("mise à jour du lien: %v", err)
+	}
+
+	stale := &models.LinkCheck{LinkID: link.ID, CheckedAt: time.Now().UTC(), Healthy: false}
+	recorded, err := checkRepo.RecordCheck(stale, "https://old.example.com", models.HealthDown)
+	if err != nil {
+		t.Fatalf("RecordCheck: %v", err)
+	}
+	if recorded {
+		t.Error("vérification de l'ancienne URL enregistrée")
+	}
+	got, err := linkRepo.GetLinkByShortCode("abc")
+	if err != nil {
+		t.Fatalf("lecture du lien: %v", err)
+	}
+	if got.HealthStatus != models.HealthUnknown {
+		t.Errorf("health_status = %q, attendu %q", got.HealthStatus, models.HealthUnknown)
+	}
+
+	current := &models.LinkCheck{LinkID: link.ID, CheckedAt: time.Now().UTC(), Healthy: true}
+	recorded, err = checkRepo.RecordCheck(current, "https://new.example.com", models.HealthUp)
+	if err != nil {
+		t.Fatalf("RecordCheck: %v", err)
+	}
+	if !recorded {
+		t.Error("vérification de la destination actuelle ignorée")
+	}
+	checks, err := checkRepo.ListChecks(LinkCheckFilter{LinkID: link.ID})
+	if err != nil {
+		t.Fatalf("ListChecks: %v", err)
+	}
+	if len(checks) != 1 || checks[0].ID != current.ID {
+		t.Errorf("historique = %+v, attendu la seule vérification de la nouvelle URL", checks)
+	}
+}
+
+func TestRecordCheckStoresLatestCertificate(t *testing.T) {
+	db := newTestDB(t)
+	linkRepo := NewLinkRepository(db)
+	checkRepo := NewLinkCheckRepository(db)
+
+	link := &models.Link{ShortCode: "tls", LongURL: "https://example.com", CreatedAt: time.Now().UTC()}
+	if err := linkRepo.CreateLink(link); err != nil {
+		t.Fatalf("création du lien: %v", err)
+	}
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	hostnameValid := false
+	check := &models.LinkCheck{
+		LinkID: link.ID, CheckedAt: time.Now().UTC(), Healthy: true,
+		TLSStatus: models.TLSInvalid, TLSExpiresAt: &expiresAt, TLSIssuer: "Example CA", TLSHostnameValid: &hostnameValid,
+	}
+	if _, err := checkRepo.RecordCheck(check, link.LongURL, models.HealthUp); err != nil {
+		t.Fatalf("RecordCheck: %v", err)
+	}
+	got, err := linkRepo.GetLinkByShortCode("tls")
+	if err != nil {
+		t.Fatalf("lecture du lien: %v", err)
+	}
+	if got.TLSIssuer != "Example CA" || got.TLSHostnameValid == nil || *got.TLSHostnameValid {
+		t.Errorf("certificat du lien = %q, %v ; attendu l'émetteur et hostname_valid=false de la vérification",
+			got.TLSIssuer, got.TLSHostnameValid)
+	}
+
+	// Une nouvelle destination oublie le certificat de l'ancienne.
+	got.LongURL = "https://other.example.com"
+	if err := linkRepo.UpdateLink(got, true); err != nil {
+		t.Fatalf("mise à jour du lien: %v", err)
+	}
+	got, err = linkRepo.GetLinkByShortCode("tls")
+	if err != nil {
+		t.Fatalf("lecture du lien: %v", err)
+	}
+	if got.TLSStatus != "" || got.TLSIssuer != "" || got.TLSHostnameValid != nil {
+		t.Errorf("certificat conservé après changement de destination : %q, %q, %v",
+			got.TLSStatus, got.TLSIssuer, got.TLSHostnameValid)
+	}
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Valeurs acceptées pour le tri de ListLinks.
+const (
+	SortByCreatedAt = "created_at" // Tri par date de création (ordre des IDs auto-incrémentés)
+	SortByClicks    = "clicks"     // Tri par nombre de clics
+)
+
+// Valeurs acceptées pour le sens du tri.
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// Bornes de la taille d'une page.
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListLinksOptions regroupe les paramètres de pagination, de tri et de filtrage de ListLinks.
+type ListLinksOptions struct {
+	Limit         int        // Nombre maximum de liens par page (DefaultListLimit si <= 0)
+	Cursor        string     // Curseur opaque renvoyé par la page précédente (vide = première page)
+	SortBy        string     // SortByCreatedAt (défaut) ou SortByClicks
+	Order         string     // OrderDesc (défaut) ou OrderAsc
+	Search        string     // Sous-chaîne recherchée dans l'URL longue
+	CreatedAfter  *time.Time // Ne garder que les liens créés à partir de cette date (tout fuseau)
+	CreatedBefore *time.Time // Ne garder que les liens créés avant cette date (tout fuseau)
+	Owner         OwnerScope // Propriétaire des liens visibles (par défaut : tous les liens)
+}
+
+// OwnerScope restreint les liens visibles selon leur propriétaire.
+// La valeur zéro ne restreint rien : c'est le cas de la CLI, d'une clé admin
+// ou de l'API lorsque l'authentification est désactivée.
+type OwnerScope struct {
+	Restricted bool  // Vrai = seuls les liens de OwnerID sont visibles
+	OwnerID    *uint // Propriétaire des liens visibles si Restricted (nil = liens sans propriétaire)
+}
+
+// OwnedBy retourne la portée limitée aux liens de l'utilisateur ownerID (nil = liens sans propriétaire).
+func OwnedBy(ownerID *uint) OwnerScope {
+	return OwnerScope{Restricted: true, OwnerID: ownerID}
+}
+
+// Allows indique si le lien est visible dans cette portée.
+func (s OwnerScope) Allows(link *models.Link) bool {
+	if !s.Restricted {
+		return true
+	}
+	if s.OwnerID == nil || link.OwnerID == nil {
+		return s.OwnerID == nil && link.OwnerID == nil
+	}
+	return *s.OwnerID == *link.OwnerID
+}
+
+// LinkListItem est un lien de la liste, avec son nombre de clics hors robots (Link.ClickCount).
+type LinkListItem struct {
+	models.Link
+}
+
+// LinkPage est une page de résultats de ListLinks.
+type LinkPage struct {
+	Items      []LinkListItem // Liens de la page
+	NextCursor string         // Curseur de la page suivante (vide s'il n'y en a pas)
+}
+
+// normalize applique les valeurs par défaut et valide les options.
+func (o *ListLinksOptions) normalize() error {
+	if o.Limit <= 0 {
+		o.Limit = DefaultListLimit
+	}
+	if o.Limit > MaxListLimit {
+		o.Limit = MaxListLimit
+	}
+	if o.SortBy == "" {
+		o.SortBy = SortByCreatedAt
+	}
+	if o.SortBy != SortByCreatedAt && o.SortBy != SortByClicks {
+		return &customerrors.ErrInvalidQuery{Param: "sort", Reason: "valeurs acceptées : created_at, clicks"}
+	}
+	if o.Order == "" {
+		o.Order = OrderDesc
+	}
+	if o.Order != OrderAsc && o.Order != OrderDesc {
+		return &customerrors.ErrInvalidQuery{Param: "order", Reason: "valeurs acceptées : asc, desc"}
+	}
+	return nil
+}
+
+// listCursor est la position d'un lien dans l'ordre de tri : la valeur triée et l'ID
+// (qui départage les égalités et rend l'ordre total).
+type listCursor struct {
+	value int64
+	id    uint
+}
+
+// encode sérialise le curseur sous une forme opaque pour le client.
+func (c listCursor) encode() string {
+	raw := fmt.Sprintf("%d:%d", c.value, c.id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeListCursor désérialise un curseur produit par listCursor.encode.
+func decodeListCursor(cursor string) (listCursor, error) {
+	invalid := &customerrors.ErrInvalidQuery{Param: "cursor", Reason: "curseur illisible"}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listCursor{}, invalid
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return listCursor{}, invalid
+	}
+	value, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return listCursor{}, invalid
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return listCursor{}, invalid
+	}
+	return listCursor{value: value, id: uint(id)}, nil
+}
+
+// escapeLike échappe les caractères spéciaux de LIKE pour une recherche littérale.
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
+
+// ListLinks récupère une page de liens, triée et filtrée selon opts.
+//
+// La pagination se fait par curseur ("keyset") plutôt que par OFFSET : chaque page reprend
+// strictement après le dernier lien de la page précédente, ce qui reste rapide et stable
+// même sur des centaines de milliers de lignes.
+// Le tri par date de création s'appuie sur l'ID auto-incrémenté, qui suit l'ordre de création,
+// et le tri par clics sur la colonne dénormalisée click_count et l'index (click_count, id).
+func (r *GormLinkRepository) ListLinks(opts ListLinksOptions) (*LinkPage, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+
+	query := r.db.Model(&models.Link{})
+
+	// Filtres
+	if opts.Owner.Restricted {
+		if opts.Owner.OwnerID == nil {
+			query = query.Where("links.owner_id IS NULL")
+		} else {
+			query = query.Where("links.owner_id = ?", *opts.Owner.OwnerID)
+		}
+	}
+	if opts.Search != "" {
+		query = query.Where(`links.long_url LIKE ? ESCAPE '\'`, "%"+escapeLike(opts.Search)+"%")
+	}
+	// Les dates sont comparées comme du texte par SQLite : created_at est stocké en UTC,
+	// les bornes doivent donc l'être aussi.
+	if opts.CreatedAfter != nil {
+		query = query.Where("links.created_at >= ?", opts.CreatedAfter.UTC())
+	}
+	if opts.CreatedBefore != nil {
+		query = query.Where("links.created_at < ?", opts.CreatedBefore.UTC())
+	}
+
+	// Reprise après le curseur, dans le sens du tri
+	cmp := "<"
+	if opts.Order == OrderAsc {
+		cmp = ">"
+	}
+	if opts.Cursor != "" {
+		cursor, err := decodeListCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if opts.SortBy == SortByClicks {
+			query = query.Where(fmt.Sprintf("(links.click_count %s ?) OR (links.click_count = ? AND links.id %s ?)", cmp, cmp),
+				cursor.value, cursor.value, cursor.id)
+		} else {
+			query = query.Where(fmt.Sprintf("links.id %s ?", cmp), cursor.id)
+		}
+	}
+
+	// Tri
+	if opts.SortBy == SortByClicks {
+		query = query.Order(fmt.Sprintf("links.click_count %s, links.id %s", opts.Order, opts.Order))
+	} else {
+		query = query.Order(fmt.Sprintf("links.id %s", opts.Order))
+	}
+
+	// On demande un élément de plus que la limite pour savoir s'il existe une page suivante.
+	var items []LinkListItem
+	if err := query.Limit(opts.Limit + 1).Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération de la liste des liens : %w", err)
+	}
+
+	page := &LinkPage{Items: items}
+	if len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		last := page.Items[len(page.Items)-1]
+		next := listCursor{id: last.ID}
+		if opts.SortBy == SortByClicks {
+			next.value = last.ClickCount
+		}
+		page.NextCursor = next.encode()
+	}
+	return page, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// createListedLinks crée un lien par URL, avec clicks[i] clics hors robots pour le i-ème.
+func createListedLinks(t *testing.T, repo *GormLinkRepository, urls []string, clicks []int64) []*models.Link {
+	t.Helper()
+	links := make([]*models.Link, len(urls))
+	for i, url := range urls {
+		link := &models.Link{ShortCode: fmt.Sprintf("l%d", i), LongURL: url, CreatedAt: time.Now().UTC()}
+		if err := repo.CreateLink(link); err != nil {
+			t.Fatalf("création du lien %d: %v", i, err)
+		}
+		if clicks != nil {
+			if err := repo.db.Model(link).UpdateColumn("click_count", clicks[i]).Error; err != nil {
+				t.Fatalf("compteur du lien %d: %v", i, err)
+			}
+			link.ClickCount = clicks[i]
+		}
+		links[i] = link
+	}
+	return links
+}
+
+// listAll parcourt toutes les pages de ListLinks et retourne les liens dans l'ordre reçu.
+// between est appelée après chaque page, avant de demander la suivante.
+func listAll(t *testing.T, repo *GormLinkRepository, opts ListLinksOptions, between func(page int)) []LinkListItem {
+	t.Helper()
+	var all []LinkListItem
+	for page := 1; ; page++ {
+		if page > 100 {
+			t.Fatal("pagination sans fin")
+		}
+		result, err := repo.ListLinks(opts)
+		if err != nil {
+			t.Fatalf("ListLinks page %d: %v", page, err)
+		}
+		if len(result.Items) > opts.Limit {
+			t.Fatalf("page %d : %d lien(s) pour une limite de %d", page, len(result.Items), opts.Limit)
+		}
+		all = append(all, result.Items...)
+		if result.NextCursor == "" {
+			return all
+		}
+		if between != nil {
+			between(page)
+		}
+		opts.Cursor = result.NextCursor
+	}
+}
+
+func TestListLinksPagesThroughTiedClickCounts(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewLinkRepository(db)
+
+	// 25 liens répartis sur trois valeurs de click_count : la plupart des frontières de page
+	// tombent au milieu d'une série d'égalités.
+	urls := make([]string, 25)
+	clicks := make([]int64, 25)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/%d", i)
+		clicks[i] = int64(i % 3)
+	}
+	createListedLinks(t, repo, urls, clicks)
+
+	for _, order := range []string{OrderDesc, OrderAsc} {
+		t.Run(order, func(t *testing.T) {
+			got := listAll(t, repo, ListLinksOptions{Limit: 4, SortBy: SortByClicks, Order: order}, nil)
+
+			seen := make(map[uint]bool)
+			for i, item := range got {
+				if seen[item.ID] {
+					t.Errorf("lien %d renvoyé deux fois", item.ID)
+				}
+				seen[item.ID] = true
+				if i == 0 {
+					continue
+				}
+				prev := got[i-1]
+				inOrder := prev.ClickCount > item.ClickCount || (prev.ClickCount == item.ClickCount && prev.ID > item.ID)
+				if order == OrderAsc {
+					inOrder = prev.ClickCount < item.ClickCount || (prev.ClickCount == item.ClickCount && prev.ID < item.ID)
+				}
+				if !inOrder {
+					t.Errorf("position %d : (%d, %d) après (%d, %d), hors de l'ordre %s",
+						i, item.ClickCount, item.ID, prev.ClickCount, prev.ID, order)
+				}
+			}
+			if len(seen) != len(urls) {
+				t.Errorf("%d lien(s) distinct(s) parcouru(s), attendu %d", len(seen), len(urls))
+			}
+		})
+	}
+}
+
+func TestListLinksCursorStableWhileClicksArrive(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewLinkRepository(db)
+	clickRepo := NewClickRepository(db)
+
+	urls := make([]string, 12)
+	clicks := make([]int64, 12)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/%d", i)
+		clicks[i] = int64(100 - 5*i) // Valeurs distinctes et espacées : 100, 95, ..., 45
+	}
+	links := createListedLinks(t, repo, urls, clicks)
+
+	// Entre deux pages, un lien déjà parcouru et un lien encore à venir reçoivent des clics (dont
+	// un d'un robot, qui n'est pas compté). Le premier remonte dans le tri : il ne doit pas être
+	// renvoyé une seconde fois. Le second reste après le curseur : il ne doit pas être sauté.
+	between := func(page int) {
+		if page != 1 {
+			return
+		}
+		now := time.Now().UTC()
+		batch := []*models.Click{
+			{LinkID: links[1].ID, Timestamp: now},
+			{LinkID: links[1].ID, Timestamp: now},
+			{LinkID: links[10].ID, Timestamp: now},
+			{LinkID: links[10].ID, Timestamp: now, IsBot: true},
+		}
+		if err := clickRepo.CreateClicks(batch); err != nil {
+			t.Fatalf("CreateClicks: %v", err)
+		}
+	}
+	got := listAll(t, repo, ListLinksOptions{Limit: 3, SortBy: SortByClicks, Order: OrderDesc}, between)
+
+	seen := make(map[uint]int)
+	for _, item := range got {
+		seen[item.ID]++
+	}
+	for i, link := range links {
+		if seen[link.ID] != 1 {
+			t.Errorf("lien %d (%d clics au départ) renvoyé %d fois, attendu 1", i, clicks[i], seen[link.ID])
+		}
+	}
+
+	var count int64
+	if err := db.Model(&models.Link{}).Where("id = ?", links[10].ID).Select("click_count").Scan(&count).Error; err != nil {
+		t.Fatalf("lecture du compteur: %v", err)
+	}
+	if count != clicks[10]+1 {
+		t.Errorf("click_count = %d, attendu %d (le clic du robot n'est pas compté)", count, clicks[10]+1)
+	}
+}
+
+func TestListLinksSearchEscapesLikeWildcards(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewLinkRepository(db)
+
+	createListedLinks(t, repo, []string{
+		"https://example.com/100%",
+		"https://example.com/1000",
+		"https://example.com/a_b",
+		"https://example.com/axb",
+		`https://example.com/back\slash`,
+		"https://example.com/backslash",
+	}, nil)
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"100%", []string{"https://example.com/100%"}},
+		{"a_b", []string{"https://example.com/a_b"}},
+		{`back\s`, []string{`https://example.com/back\slash`}},
+		{"%", []string{"https://example.com/100%"}},
+		{"_", []string{"https://example.com/a_b"}},
+	}
+	for _, tt := range tests {
+		page, err := repo.ListLinks(ListLinksOptions{Search: tt.search, Order: OrderAsc})
+		if err != nil {
+			t.Fatalf("ListLinks(%q): %v", tt.search, err)
+		}
+		var got []string
+		for _, item := range page.Items {
+			got = append(got, item.LongURL)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("recherche %q : %v, attendu %v", tt.search, got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
@@ -16,18 +18,68 @@ import (
 // - Respecte le principe SOLID "Dependency Inversion Principle"
 type LinkRepository interface {
 	// CreateLink insère un nouveau lien dans la base de données
+	// Retourne gorm.ErrDuplicatedKey si le code court est déjà pris (contrainte d'unicité)
 	CreateLink(link *models.Link) error
 	
 	// GetLinkByShortCode récupère un lien par son code court unique
 	// Retourne gorm.ErrRecordNotFound si non trouvé
 	GetLinkByShortCode(shortCode string) (*models.Link, error)
 	
-	// GetAllLinks récupère tous les liens de la base de données
-	// Utilisé par le moniteur pour vérifier toutes les URLs
-	GetAllLinks() ([]models.Link, error)
+	// FindActiveLinkByURLHash récupère le plus ancien lien actif (non expiré, sans date d'expiration)
+	// de ownerID (nil = sans propriétaire) dont l'URL normalisée a l'empreinte urlHash
+	// Retourne gorm.ErrRecordNotFound si aucun lien ne correspond
+	FindActiveLinkByURLHash(ownerID *uint, urlHash string) (*models.Link, error)
+
+	// SetURLHash enregistre l'empreinte de l'URL normalisée d'un lien
+	// Utilisé par la commande 'migrate' pour compléter les liens créés avant son introduction
+	SetURLHash(id uint, urlHash string) error
+
+	// GetLinksBatch récupère au plus limit liens d'ID strictement supérieur à afterID, triés par ID
+	// Utilisé par le moniteur pour parcourir tous les liens par lots sans tout charger en mémoire
+	GetLinksBatch(afterID uint, limit int) ([]models.Link, error)
+
+	// ListLinks récupère une page de liens filtrée et triée, avec le nombre de clics de chaque lien
+	// Utilisé par l'API et la commande 'list' (pagination par curseur)
+	ListLinks(opts ListLinksOptions) (*LinkPage, error)
 	
 	// CountClicksByLinkID compte le nombre total de clics pour un lien donné
-	CountClicksByLinkID(linkID uint) (int, error)
+	// Les clics de robots ne sont comptés que si includeBots est vrai
+	CountClicksByLinkID(linkID uint, includeBots bool) (int, error)
+
+	// UpdateLink enregistre l'URL de destination et la politique en cas de panne d'un lien existant
+	// Les colonnes tenues par le moniteur ne sont remises à zéro que si resetHealth est vrai (nouvelle destination) ;
+	// celles du balayage des expirations et le propriétaire ne sont jamais touchés
+	UpdateLink(link *models.Link, resetHealth bool) error
+
+	// SetHealthThresholds enregistre les seuils d'échecs et de succès propres à un lien (nil = valeur globale)
+	SetHealthThresholds(id uint, failure, recovery *int) error
+
+	// SetOwner transfère le lien d'ID donné à ownerID (nil = sans propriétaire)
+	SetOwner(id uint, ownerID *uint) error
+
+	// DeleteLink supprime (de façon douce) le lien d'ID donné
+	// Les clics associés sont conservés
+	DeleteLink(id uint) error
+
+	// MaxLinkID retourne le plus grand ID de lien attribué, liens supprimés compris (0 si aucun)
+	// Utilisé pour initialiser le compteur des générateurs de codes séquentiels
+	MaxLinkID() (uint, error)
+
+	// ReassignLinks transfère à toOwnerID tous les liens de fromOwnerID (nil = sans propriétaire)
+	// Retourne le nombre de liens transférés
+	ReassignLinks(fromOwnerID, toOwnerID *uint) (int64, error)
+
+	// RecountClicks recalcule le nombre de clics hors robots (click_count) de tous les liens
+	// Utilisé par la commande 'migrate' lors de l'ajout de la colonne
+	RecountClicks() error
+
+	// NormalizeCreatedAt convertit en UTC les dates de création enregistrées dans un autre fuseau
+	// Utilisé par la commande 'migrate' ; retourne le nombre de liens convertis
+	NormalizeCreatedAt() (int64, error)
+
+	// ExpireLinks marque comme expirés les liens dont la date d'expiration est dépassée
+	// Retourne le nombre de liens nouvellement expirés
+	ExpireLinks(now time.Time) (int64, error)
 }
 
 // GormLinkRepository est l'implémentation de LinkRepository utilisant GORM.
@@ -53,11 +105,26 @@ func (r *GormLinkRepository) CreateLink(link *models.Link) error {
 	// 3. Remplir link.CreatedAt si c'est un champ time.Time
 	result := r.db.Create(link)
 	if result.Error != nil {
+		// La contrainte d'unicité de short_code est la seule garantie fiable contre les collisions :
+		// deux créations simultanées peuvent toutes deux constater qu'un code est libre.
+		if isDuplicateKey(r.db, result.Error) {
+			return fmt.Errorf("le code court '%s' est déjà pris : %w", link.ShortCode, gorm.ErrDuplicatedKey)
+		}
 		return fmt.Errorf("erreur lors de la création du lien : %w", result.Error)
 	}
 	return nil
 }
 
+// isDuplicateKey indique si err est une violation de contrainte d'unicité.
+// Le dialecte GORM traduit le code d'erreur propre au driver (SQLITE_CONSTRAINT_UNIQUE pour SQLite).
+func isDuplicateKey(db *gorm.DB, err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	translator, ok := db.Dialector.(gorm.ErrorTranslator)
+	return ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+}
+
 // GetLinkByShortCode récupère un lien de la base de données en utilisant son shortCode.
 // Il renvoie gorm.ErrRecordNotFound si aucun lien n'est trouvé avec ce shortCode.
 func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
@@ -73,29 +140,191 @@ func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link,
 	return &link, nil
 }
 
-// GetAllLinks récupère tous les liens de la base de données.
-// Cette méthode est utilisée par le moniteur d'URLs pour vérifier l'état de toutes les URLs.
-func (r *GormLinkRepository) GetAllLinks() ([]models.Link, error) {
+// GetLinksBatch récupère au plus limit liens dont l'ID est strictement supérieur à afterID.
+// En rappelant la méthode avec l'ID du dernier lien obtenu, on parcourt toute la table
+// par lots de taille fixe (pagination "keyset", efficace même sur de grosses tables).
+func (r *GormLinkRepository) GetLinksBatch(afterID uint, limit int) ([]models.Link, error) {
 	var links []models.Link
-	// db.Find() génère : SELECT * FROM links
-	// Find() récupère tous les enregistrements et les mappe dans le slice
-	result := r.db.Find(&links)
+	// Génère : SELECT * FROM links WHERE id > ? AND deleted_at IS NULL ORDER BY id LIMIT ?
+	result := r.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&links)
 	if result.Error != nil {
-		return nil, fmt.Errorf("erreur lors de la récupération de tous les liens : %w", result.Error)
+		return nil, fmt.Errorf("erreur lors de la récupération d'un lot de liens : %w", result.Error)
 	}
 	return links, nil
 }
 
+// FindActiveLinkByURLHash récupère le plus ancien lien actif d'un propriétaire pour une URL normalisée.
+// Seuls les liens sans date d'expiration sont retenus : un lien dédupliqué ne doit pas expirer à la place
+// de celui qui était demandé.
+func (r *GormLinkRepository) FindActiveLinkByURLHash(ownerID *uint, urlHash string) (*models.Link, error) {
+	var link models.Link
+	// S'appuie sur l'index composite idx_links_owner_url_hash
+	query := r.db.Where("url_hash = ? AND expired = ? AND expires_at IS NULL", urlHash, false)
+	if ownerID == nil {
+		query = query.Where("owner_id IS NULL")
+	} else {
+		query = query.Where("owner_id = ?", *ownerID)
+	}
+	if err := query.Order("id").First(&link).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la recherche d'un lien existant : %w", err)
+	}
+	return &link, nil
+}
+
+// SetURLHash enregistre l'empreinte de l'URL normalisée d'un lien.
+func (r *GormLinkRepository) SetURLHash(id uint, urlHash string) error {
+	// UpdateColumn : seul url_hash est modifié, sans hook
+	result := r.db.Model(&models.Link{}).Where("id = ?", id).UpdateColumn("url_hash", urlHash)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la mise à jour de l'empreinte du lien %d : %w", id, result.Error)
+	}
+	return nil
+}
+
 // CountClicksByLinkID compte le nombre total de clics pour un ID de lien donné.
-// Cette méthode compte les enregistrements dans la table 'clicks' où link_id = linkID.
-func (r *GormLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
+// Cette méthode compte les enregistrements dans la table 'clicks' où link_id = linkID,
+// en excluant les clics de robots (is_bot) sauf si includeBots est vrai.
+func (r *GormLinkRepository) CountClicksByLinkID(linkID uint, includeBots bool) (int, error) {
 	var count int64 // GORM retourne un int64 pour les comptes
 	// db.Model(&models.Click{}) spécifie quelle table utiliser ('clicks')
 	// .Where("link_id = ?", linkID) filtre les clics pour ce lien spécifique
-	// .Count(&count) génère : SELECT COUNT(*) FROM clicks WHERE link_id = ?
-	result := r.db.Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count)
+	// .Count(&count) génère : SELECT COUNT(*) FROM clicks WHERE link_id = ? [AND is_bot = false]
+	query := r.db.Model(&models.Click{}).Where("link_id = ?", linkID)
+	if !includeBots {
+		query = query.Where("is_bot = ?", false)
+	}
+	result := query.Count(&count)
 	if result.Error != nil {
 		return 0, fmt.Errorf("erreur lors du comptage des clics pour LinkID %d : %w", linkID, result.Error)
 	}
 	return int(count), nil // Convertit int64 en int
 }
+
+// ExpireLinks marque comme expirés (expired = true) tous les liens dont expires_at est dépassé.
+// Les lignes ne sont pas supprimées : l'historique des clics reste consultable.
+func (r *GormLinkRepository) ExpireLinks(now time.Time) (int64, error) {
+	// Génère : UPDATE links SET expired = true WHERE expires_at IS NOT NULL AND expires_at <= ? AND expired = false
+	result := r.db.Model(&models.Link{}).
+		Where("expires_at IS NOT NULL AND expires_at <= ? AND expired = ?", now, false).
+		Update("expired", true)
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors de l'expiration des liens : %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// MaxLinkID retourne le plus grand ID de lien attribué, liens supprimés compris (0 si aucun).
+func (r *GormLinkRepository) MaxLinkID() (uint, error) {
+	var maxID uint
+	// Unscoped : les liens supprimés (suppression douce) ont consommé leur ID
+	if err := r.db.Unscoped().Model(&models.Link{}).Select("COALESCE(MAX(id), 0)").Scan(&maxID).Error; err != nil {
+		return 0, fmt.Errorf("erreur lors de la récupération du plus grand ID de lien : %w", err)
+	}
+	return maxID, nil
+}
+
+// RecountClicks recalcule links.click_count à partir de la table clicks, liens supprimés compris.
+func (r *GormLinkRepository) RecountClicks() error {
+	// Génère : UPDATE links SET click_count = (SELECT COUNT(*) FROM clicks WHERE ...)
+	result := r.db.Unscoped().Model(&models.Link{}).Where("1 = 1").UpdateColumn("click_count",
+		gorm.Expr("(SELECT COUNT(*) FROM clicks WHERE clicks.link_id = links.id AND clicks.is_bot = ?)", false))
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors du recalcul du nombre de clics des liens : %w", result.Error)
+	}
+	return nil
+}
+
+// NormalizeCreatedAt réécrit en UTC les dates de création stockées avec un autre décalage horaire
+// (liens créés avant que CreatedAt soit enregistré en UTC). SQLite compare ces dates comme du texte :
+// sans cette conversion, les filtres par date de création seraient décalés pour ces liens.
+func (r *GormLinkRepository) NormalizeCreatedAt() (int64, error) {
+	// strftime() interprète le décalage horaire (+02:00...) et produit l'heure UTC correspondante
+	result := r.db.Unscoped().Model(&models.Link{}).Where("created_at NOT LIKE ?", "%+00:00").
+		UpdateColumn("created_at", gorm.Expr("strftime('%Y-%m-%d %H:%M:%f+00:00', created_at)"))
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors de la conversion en UTC des dates de création : %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ReassignLinks transfère à toOwnerID tous les liens (expirés compris) de fromOwnerID.
+// Un ID nil désigne les liens sans propriétaire.
+func (r *GormLinkRepository) ReassignLinks(fromOwnerID, toOwnerID *uint) (int64, error) {
+	query := r.db.Model(&models.Link{})
+	if fromOwnerID == nil {
+		query = query.Where("owner_id IS NULL")
+	} else {
+		query = query.Where("owner_id = ?", *fromOwnerID)
+	}
+	// UpdateColumn : seul owner_id est modifié, sans hook
+	result := query.UpdateColumn("owner_id", toOwnerID)
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors du transfert des liens : %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// UpdateLink enregistre les colonnes modifiables d'un lien existant (destination et politique en cas de panne).
+// Le lien doit avoir été chargé au préalable (son ID doit être renseigné). Les autres colonnes ne sont
+// pas réécrites : une copie chargée avant une vérification du moniteur n'écrase pas son résultat.
+// Avec resetHealth, l'état de santé du lien est oublié (les vérifications portaient sur l'ancienne destination).
+func (r *GormLinkRepository) UpdateLink(link *models.Link, resetHealth bool) error {
+	columns := map[string]interface{}{
+		"long_url":     link.LongURL,
+		"url_hash":     link.URLHash,
+		"down_policy":  link.DownPolicy,
+		"fallback_url": link.FallbackURL,
+	}
+	if resetHealth {
+		columns["health_status"] = models.HealthUnknown
+		columns["last_checked_at"] = nil
+		columns["final_url"] = ""
+		columns["tls_status"] = ""
+		columns["tls_expires_at"] = nil
+		columns["tls_issuer"] = ""
+		columns["tls_hostname_valid"] = nil
+	}
+	// UpdateColumns : seules les colonnes listées sont modifiées, sans hook
+	result := r.db.Model(&models.Link{}).Where("id = ?", link.ID).UpdateColumns(columns)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la mise à jour du lien '%s' : %w", link.ShortCode, result.Error)
+	}
+	return nil
+}
+
+// SetHealthThresholds enregistre les seuils de santé propres au lien d'ID donné.
+func (r *GormLinkRepository) SetHealthThresholds(id uint, failure, recovery *int) error {
+	// UpdateColumns avec une map : un seuil nil est bien écrit NULL
+	result := r.db.Model(&models.Link{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"failure_threshold":  failure,
+		"recovery_threshold": recovery,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la mise à jour des seuils du lien %d : %w", id, result.Error)
+	}
+	return nil
+}
+
+// SetOwner transfère le lien d'ID donné à ownerID.
+func (r *GormLinkRepository) SetOwner(id uint, ownerID *uint) error {
+	// UpdateColumn : seul owner_id est modifié, sans hook
+	result := r.db.Model(&models.Link{}).Where("id = ?", id).UpdateColumn("owner_id", ownerID)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors du transfert du lien %d : %w", id, result.Error)
+	}
+	return nil
+}
+
+// DeleteLink supprime le lien d'ID donné.
+// Grâce au champ DeletedAt, GORM effectue une suppression douce :
+// UPDATE links SET deleted_at = ? WHERE id = ? (les clics restent en base).
+func (r *GormLinkRepository) DeleteLink(id uint) error {
+	result := r.db.Delete(&models.Link{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la suppression du lien %d : %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("erreur lors de la suppression du lien %d : %w", id, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// UserRepository définit les méthodes d'accès aux utilisateurs (table 'users').
+type UserRepository interface {
+	// CreateUser insère un nouvel utilisateur
+	CreateUser(user *models.User) error
+
+	// GetUserByName récupère un utilisateur par son nom
+	// Retourne gorm.ErrRecordNotFound si non trouvé
+	GetUserByName(name string) (*models.User, error)
+
+	// GetUserByID récupère un utilisateur par son ID
+	// Retourne gorm.ErrRecordNotFound si non trouvé
+	GetUserByID(id uint) (*models.User, error)
+
+	// ListUsers retourne tous les utilisateurs, triés par nom
+	ListUsers() ([]models.User, error)
+}
+
+// GormUserRepository est l'implémentation de UserRepository utilisant GORM.
+type GormUserRepository struct {
+	db *gorm.DB // Connexion à la base de données GORM
+}
+
+// NewUserRepository crée et retourne une nouvelle instance de GormUserRepository.
+func NewUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{db: db}
+}
+
+// CreateUser insère un nouvel utilisateur dans la base de données.
+func (r *GormUserRepository) CreateUser(user *models.User) error {
+	if err := r.db.Create(user).Error; err != nil {
+		return fmt.Errorf("erreur lors de la création de l'utilisateur '%s' : %w", user.Name, err)
+	}
+	return nil
+}
+
+// GetUserByName récupère un utilisateur par son nom.
+func (r *GormUserRepository) GetUserByName(name string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("name = ?", name).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération de l'utilisateur '%s' : %w", name, err)
+	}
+	return &user, nil
+}
+
+// GetUserByID récupère un utilisateur par son ID.
+func (r *GormUserRepository) GetUserByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération de l'utilisateur %d : %w", id, err)
+	}
+	return &user, nil
+}
+
+// ListUsers retourne tous les utilisateurs, triés par nom.
+func (r *GormUserRepository) ListUsers() ([]models.User, error) {
+	var users []models.User
+	if err := r.db.Order("name").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des utilisateurs : %w", err)
+	}
+	return users, nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix préfixe toutes les clés d'API, pour les reconnaître (ex: dans un dépôt de code).
+const APIKeyPrefix = "usk_"
+
+// apiKeyRandomBytes est le nombre d'octets aléatoires d'une clé (encodés en hexadécimal).
+const apiKeyRandomBytes = 24
+
+// apiKeyDisplayLength est le nombre de caractères de la clé conservés en clair pour l'identifier.
+const apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+// apiKeyTouchInterval limite l'écriture de la date de dernière utilisation d'une clé
+// à une fois par intervalle, pour ne pas écrire en base à chaque requête.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService gère la création, la révocation et la vérification des clés d'API.
+type APIKeyService struct {
+	keyRepo repository.APIKeyRepository
+}
+
+// NewAPIKeyService crée et retourne une nouvelle instance de APIKeyService.
+func NewAPIKeyService(keyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		keyRepo: keyRepo,
+	}
+}
+
+// HashAPIKey retourne l'empreinte SHA-256 (hexadécimale) d'une clé, telle que stockée en base.
+// Les clés étant longues et aléatoires, un hachage lent (bcrypt...) n'apporterait rien.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseScopes valide et normalise une liste de permissions séparées par des virgules (ex: "links:read,stats:read").
+func ParseScopes(value string) ([]string, error) {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, scope := range strings.Split(value, ",") {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" || seen[scope] {
+			continue
+		}
+		if !models.IsValidScope(scope) {
+			return nil, &customerrors.ErrInvalidScope{Scope: scope}
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes, nil
+}
+
+// CreateAPIKey crée une clé d'API portant les permissions données, agissant pour le compte
+// de l'utilisateur userID (nil = liens sans propriétaire).
+// La valeur de la clé n'est retournée qu'ici : seule son empreinte est enregistrée.
+func (s *APIKeyService) CreateAPIKey(name string, scopes []string, userID *uint) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", &customerrors.ErrInvalidQuery{Param: "name", Reason: "le nom de la clé est requis"}
+	}
+	if len(scopes) == 0 {
+		return nil, "", &customerrors.ErrInvalidQuery{Param: "scopes", Reason: "au moins une permission est requise"}
+	}
+	for _, scope := range scopes {
+		if !models.IsValidScope(scope) {
+			return nil, "", &customerrors.ErrInvalidScope{Scope: scope}
+		}
+	}
+
+	random := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", fmt.Errorf("erreur lors de la génération de la clé d'API: %w", err)
+	}
+	plain := APIKeyPrefix + hex.EncodeToString(random)
+
+	key := &models.APIKey{
+		Name:    name,
+		Prefix:  plain[:apiKeyDisplayLength],
+		KeyHash: HashAPIKey(plain),
+		Scopes:  strings.Join(scopes, ","),
+		UserID:  userID,
+	}
+	if err := s.keyRepo.CreateAPIKey(key); err != nil {
+		return nil, "", fmt.Errorf("erreur lors de la création de la clé d'API: %w", err)
+	}
+	return key, plain, nil
+}
+
+// ListAPIKeys retourne toutes les clés d'API, révoquées comprises.
+func (s *APIKeyService) ListAPIKeys() ([]models.APIKey, error) {
+	keys, err := s.keyRepo.ListAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des clés d'API: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey révoque la clé d'ID donné : elle est refusée dès la requête suivante.
+func (s *APIKeyService) RevokeAPIKey(id uint) error {
+	if err := s.keyRepo.RevokeAPIKey(id, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &customerrors.ErrAPIKeyNotFound{ID: id}
+		}
+		return fmt.Errorf("erreur lors de la révocation de la clé d'API: %w", err)
+	}
+	return nil
+}
+
+// Authenticate retourne la clé d'API correspondant à la valeur fournie par un client.
+// Retourne ErrInvalidAPIKey si la clé est inconnue ou révoquée.
+func (s *APIKeyService) Authenticate(plain string) (*models.APIKey, error) {
+	if !strings.HasPrefix(plain, APIKeyPrefix) {
+		return nil, &customerrors.ErrInvalidAPIKey{}
+	}
+	key, err := s.keyRepo.GetAPIKeyByHash(HashAPIKey(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &customerrors.ErrInvalidAPIKey{}
+		}
+		return nil, fmt.Errorf("erreur lors de la vérification de la clé d'API: %w", err)
+	}
+	if key.IsRevoked() {
+		return nil, &customerrors.ErrInvalidAPIKey{}
+	}
+
+	now := time.Now().UTC()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// Un échec d'écriture n'empêche pas la requête : la date n'est qu'indicative.
+		if err := s.keyRepo.TouchAPIKey(key.ID, now); err != nil {
+			log.Printf("Warning: could not update last use of API key %d: %v", key.ID, err)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
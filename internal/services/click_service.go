@@ -2,7 +2,10 @@ package services
 
 import (
 	"fmt"
+	"math"
+	"time"
 
+	"github.com/axellelanca/urlshortener/internal/customerrors"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le package repository
 )
@@ -39,3 +42,190 @@ func (s *ClickService) GetClicksCountByLinkID(linkID uint) (int, error) {
 	}
 	return count, nil
 }
+
+// maxTimeSeriesPoints borne le nombre de tranches d'une série temporelle
+// (ex: 90 jours par heure = 2160 points) pour éviter des réponses démesurées.
+const maxTimeSeriesPoints = 2000
+
+// defaultTimeSeriesSpans donne la période analysée par défaut (si 'from' est absent) pour chaque granularité.
+var defaultTimeSeriesSpans = map[string]time.Duration{
+	repository.GranularityHour: 24 * time.Hour,
+	repository.GranularityDay:  30 * 24 * time.Hour,
+	repository.GranularityWeek: 12 * 7 * 24 * time.Hour,
+}
+
+// TimeSeriesPoint est le nombre de clics d'une tranche de temps d'une série.
+type TimeSeriesPoint struct {
+	Start time.Time `json:"start"` // Début de la tranche (UTC)
+	Count int64     `json:"count"` // Nombre de clics dans la tranche
+}
+
+// TimeSeries est une série temporelle de clics, continue (les tranches sans clic valent 0).
+type TimeSeries struct {
+	Granularity string            `json:"granularity"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Total       int64             `json:"total"`
+	Points      []TimeSeriesPoint `json:"points"`
+}
+
+// GetClickTimeSeries calcule la série temporelle des clics correspondant au filtre.
+// filter.From et filter.To peuvent être nuls : To vaut alors maintenant et From une période
+// par défaut dépendant de la granularité. From est aligné sur le début de sa tranche.
+func (s *ClickService) GetClickTimeSeries(filter repository.ClickFilter, granularity string) (*TimeSeries, error) {
+	if granularity == "" {
+		granularity = repository.GranularityDay
+	}
+	span, ok := defaultTimeSeriesSpans[granularity]
+	if !ok {
+		return nil, &customerrors.ErrInvalidQuery{Param: "granularity", Reason: "valeurs acceptées : hour, day, week"}
+	}
+	from, to := filter.From, filter.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-span)
+	}
+	from, to = truncateToBucket(from.UTC(), granularity), to.UTC()
+	if !from.Before(to) {
+		return nil, &customerrors.ErrInvalidQuery{Param: "from", Reason: "doit être antérieur à 'to'"}
+	}
+
+	// Construire la liste complète des tranches de la période, initialisées à 0.
+	series := &TimeSeries{Granularity: granularity, From: from, To: to}
+	index := make(map[string]int)
+	for start := from; start.Before(to); start = nextBucket(start, granularity) {
+		if len(series.Points) >= maxTimeSeriesPoints {
+			return nil, &customerrors.ErrInvalidQuery{
+				Param:  "granularity",
+				Reason: fmt.Sprintf("la période demandée dépasse %d tranches, choisissez une granularité plus large", maxTimeSeriesPoints),
+			}
+		}
+		index[start.Format(repository.BucketLayout)] = len(series.Points)
+		series.Points = append(series.Points, TimeSeriesPoint{Start: start})
+	}
+
+	filter.From, filter.To = from, to
+	buckets, err := s.clickRepo.CountClicksByBucket(filter, granularity)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du calcul de la série temporelle: %w", err)
+	}
+	for _, bucket := range buckets {
+		if i, ok := index[bucket.Bucket]; ok {
+			series.Points[i].Count = bucket.Count
+			series.Total += bucket.Count
+		}
+	}
+	return series, nil
+}
+
+// truncateToBucket ramène un instant UTC au début de sa tranche (heure, jour ou lundi de la semaine).
+func truncateToBucket(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case repository.GranularityHour:
+		return t.Truncate(time.Hour)
+	case repository.GranularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// nextBucket retourne le début de la tranche suivante.
+func nextBucket(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case repository.GranularityHour:
+		return start.Add(time.Hour)
+	case repository.GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// BreakdownEntry est la part des clics correspondant à une valeur d'une dimension.
+type BreakdownEntry struct {
+	Value   string  `json:"value"`   // Valeur de la dimension (ex: "mobile")
+	Count   int64   `json:"count"`   // Nombre de clics
+	Percent float64 `json:"percent"` // Pourcentage du total, arrondi à 0.1
+}
+
+// Breakdown est la répartition des clics d'un lien selon une dimension.
+type Breakdown struct {
+	Dimension string           `json:"dimension"`
+	Total     int64            `json:"total"`
+	Entries   []BreakdownEntry `json:"entries"`
+}
+
+// GetClickBreakdown calcule la répartition des clics correspondant au filtre selon une dimension
+// (browser, os ou device).
+func (s *ClickService) GetClickBreakdown(filter repository.ClickFilter, dimension string) (*Breakdown, error) {
+	if !repository.IsValidDimension(dimension) {
+		return nil, &customerrors.ErrInvalidQuery{Param: "dimension", Reason: "valeurs acceptées : browser, os, device"}
+	}
+
+	counts, err := s.clickRepo.CountClicksByDimension(filter, dimension)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du calcul de la répartition: %w", err)
+	}
+	return newBreakdown(dimension, counts), nil
+}
+
+// DirectReferrer est le libellé des clics sans en-tête Referer (lien tapé, favori, application...).
+const DirectReferrer = "(direct)"
+
+// defaultTopReferrers est le nombre de référents retournés par défaut par GetTopReferrers.
+const defaultTopReferrers = 10
+
+// GetTopReferrers retourne les sites référents ayant généré le plus de clics correspondant au filtre.
+// Les pourcentages sont calculés sur le total des clics de la période, pas seulement sur le top.
+func (s *ClickService) GetTopReferrers(filter repository.ClickFilter, limit int) (*Breakdown, error) {
+	if limit <= 0 {
+		limit = defaultTopReferrers
+	}
+
+	counts, err := s.clickRepo.CountTopReferrers(filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du classement des référents: %w", err)
+	}
+	total, err := s.clickRepo.CountClicks(filter)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du comptage des clics: %w", err)
+	}
+
+	for i := range counts {
+		if counts[i].Value == "" {
+			counts[i].Value = DirectReferrer
+		}
+	}
+	return newBreakdownWithTotal("referrer", counts, total), nil
+}
+
+// newBreakdown construit une répartition (avec pourcentages) à partir des comptes agrégés.
+func newBreakdown(dimension string, counts []repository.DimensionCount) *Breakdown {
+	var total int64
+	for _, c := range counts {
+		total += c.Count
+	}
+	return newBreakdownWithTotal(dimension, counts, total)
+}
+
+// newBreakdownWithTotal construit une répartition dont les pourcentages sont rapportés à total.
+func newBreakdownWithTotal(dimension string, counts []repository.DimensionCount, total int64) *Breakdown {
+	breakdown := &Breakdown{Dimension: dimension, Total: total, Entries: make([]BreakdownEntry, 0, len(counts))}
+	for _, c := range counts {
+		entry := BreakdownEntry{Value: c.Value, Count: c.Count}
+		if entry.Value == "" {
+			// Clics enregistrés avant l'ajout de la dimension
+			entry.Value = "unknown"
+		}
+		if breakdown.Total > 0 {
+			entry.Percent = math.Round(float64(c.Count)*1000/float64(breakdown.Total)) / 10
+		}
+		breakdown.Entries = append(breakdown.Entries, entry)
+	}
+	return breakdown
+}
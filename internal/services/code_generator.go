@@ -0,0 +1,311 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/bits"
+	mathrand "math/rand/v2"
+	"strings"
+	"sync"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Stratégies de génération des codes courts (clé shortcode.strategy de la configuration).
+const (
+	CodeStrategyRandom        = "random"        // Caractères tirés au hasard dans l'alphabet configuré
+	CodeStrategySequential    = "sequential"    // Compteur encodé en base62 (codes courts et prévisibles)
+	CodeStrategyHashids       = "hashids"       // Compteur brouillé : codes de longueur fixe, sans ordre apparent
+	CodeStrategyPronounceable = "pronounceable" // Alternance consonnes/voyelles, sans caractère ambigu (0/O/l/1)
+)
+
+// Valeurs par défaut et bornes des options des générateurs.
+const (
+	DefaultCodeLength = 6
+	maxCodeLength     = 32 // Même borne que la longueur d'un alias
+
+	// maxCounterCodeLength est la longueur maximale des codes à compteur : 62^10 tient dans un uint64.
+	maxCounterCodeLength = 10
+)
+
+// UnambiguousAlphabet est un alphabet sans les caractères qui se confondent une fois imprimés
+// (0/O/o, 1/l/I), utilisable avec la stratégie "random".
+const UnambiguousAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Lettres de la stratégie "pronounceable" (minuscules, sans l, o ni i).
+const (
+	pronounceableConsonants = "bcdfghjkmnprstvwz"
+	pronounceableVowels     = "aeuy"
+)
+
+// collisionsPerExtraChar est le nombre de tentatives infructueuses après lequel les générateurs
+// aléatoires allongent le code d'un caractère : si les collisions s'accumulent, l'espace des codes
+// de cette longueur est trop rempli.
+const collisionsPerExtraChar = 2
+
+// CodeGenerator produit les codes courts des nouveaux liens.
+// attempt est le numéro de la tentative pour un même lien (0 pour la première) : chaque nouvel
+// appel fait suite à une collision, ce qui permet au générateur de s'adapter (allonger le code...).
+// Les implémentations doivent pouvoir être appelées depuis plusieurs goroutines.
+type CodeGenerator interface {
+	Generate(attempt int) (string, error)
+}
+
+// CodeGeneratorOptions regroupe les paramètres de NewCodeGenerator.
+type CodeGeneratorOptions struct {
+	Strategy string // Une des constantes CodeStrategy* (CodeStrategyRandom si vide)
+	Length   int    // Longueur des codes, minimale pour sequential et hashids (DefaultCodeLength si <= 0)
+	Alphabet string // Caractères utilisés par la stratégie random (base62 si vide)
+	Salt     string // Secret qui détermine le brouillage de la stratégie hashids
+}
+
+// NewCodeGenerator crée le générateur correspondant à la stratégie demandée.
+// linkRepo sert aux stratégies à compteur, qui reprennent après le plus grand ID de lien existant.
+func NewCodeGenerator(opts CodeGeneratorOptions, linkRepo repository.LinkRepository) (CodeGenerator, error) {
+	if opts.Length <= 0 {
+		opts.Length = DefaultCodeLength
+	}
+	if opts.Length > maxCodeLength {
+		return nil, fmt.Errorf("longueur de code invalide %d (maximum %d)", opts.Length, maxCodeLength)
+	}
+
+	switch opts.Strategy {
+	case "", CodeStrategyRandom:
+		alphabet := opts.Alphabet
+		if alphabet == "" {
+			alphabet = charset
+		}
+		if err := validateAlphabet(alphabet); err != nil {
+			return nil, err
+		}
+		return NewRandomCodeGenerator(alphabet, opts.Length), nil
+	case CodeStrategyPronounceable:
+		return &pronounceableGenerator{length: opts.Length}, nil
+	case CodeStrategySequential, CodeStrategyHashids:
+		if opts.Length > maxCounterCodeLength {
+			return nil, fmt.Errorf("longueur de code invalide %d pour %s (maximum %d)", opts.Length, opts.Strategy, maxCounterCodeLength)
+		}
+		if opts.Strategy == CodeStrategySequential {
+			return &sequentialGenerator{counter: newCodeCounter(linkRepo), length: opts.Length}, nil
+		}
+		return newHashidsGenerator(newCodeCounter(linkRepo), opts.Length, opts.Salt), nil
+	default:
+		return nil, fmt.Errorf("stratégie de code court inconnue '%s' (valeurs possibles : random, sequential, hashids, pronounceable)", opts.Strategy)
+	}
+}
+
+// validateAlphabet vérifie qu'un alphabet ne contient que des caractères utilisables dans un chemin d'URL,
+// sans doublon, et au moins deux caractères.
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 || !aliasPattern.MatchString(alphabet) {
+		return fmt.Errorf("alphabet de code court invalide '%s' : au moins 2 caractères parmi les lettres, chiffres, '-' et '_'", alphabet)
+	}
+	for i := range alphabet {
+		if strings.IndexByte(alphabet[i+1:], alphabet[i]) >= 0 {
+			return fmt.Errorf("alphabet de code court invalide : le caractère '%c' est en double", alphabet[i])
+		}
+	}
+	return nil
+}
+
+// randomCode tire length caractères au hasard dans alphabet.
+// Il utilise le package 'crypto/rand' pour éviter la prévisibilité.
+func randomCode(alphabet string, length int) (string, error) {
+	result := make([]byte, length)
+	alphabetLen := big.NewInt(int64(len(alphabet)))
+
+	for i := 0; i < length; i++ {
+		// Génère un nombre aléatoire sécurisé entre 0 et len(alphabet)-1
+		randomIndex, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", fmt.Errorf("erreur lors de la génération du code aléatoire: %w", err)
+		}
+		result[i] = alphabet[randomIndex.Int64()]
+	}
+	return string(result), nil
+}
+
+// RandomCodeGenerator tire des codes au hasard dans un alphabet.
+// La longueur augmente d'un caractère toutes les collisionsPerExtraChar tentatives infructueuses.
+type RandomCodeGenerator struct {
+	alphabet string
+	length   int
+}
+
+// NewRandomCodeGenerator crée un générateur aléatoire (l'alphabet doit avoir été validé).
+func NewRandomCodeGenerator(alphabet string, length int) *RandomCodeGenerator {
+	return &RandomCodeGenerator{alphabet: alphabet, length: length}
+}
+
+// Generate implémente CodeGenerator.
+func (g *RandomCodeGenerator) Generate(attempt int) (string, error) {
+	return randomCode(g.alphabet, growLength(g.length, attempt))
+}
+
+// growLength retourne la longueur à utiliser pour la tentative attempt.
+func growLength(length, attempt int) int {
+	return min(length+attempt/collisionsPerExtraChar, maxCodeLength)
+}
+
+// pronounceableGenerator produit des codes faciles à lire et à dicter ("bakuteso") :
+// consonnes et voyelles alternées, en minuscules, sans caractère ambigu.
+type pronounceableGenerator struct {
+	length int
+}
+
+// Generate implémente CodeGenerator.
+func (g *pronounceableGenerator) Generate(attempt int) (string, error) {
+	length := growLength(g.length, attempt)
+	var code strings.Builder
+	code.Grow(length)
+	for i := 0; i < length; i++ {
+		letters := pronounceableConsonants
+		if i%2 == 1 {
+			letters = pronounceableVowels
+		}
+		letter, err := randomCode(letters, 1)
+		if err != nil {
+			return "", err
+		}
+		code.WriteString(letter)
+	}
+	return code.String(), nil
+}
+
+// codeCounter est le compteur partagé des stratégies sequential et hashids.
+// Il n'est pas persisté : au premier appel, il reprend après le plus grand ID de lien existant
+// (liens supprimés compris). Chaque lien consomme un ID, donc au moins une valeur du compteur.
+// Après une collision (liens créés par un autre processus, la CLI par exemple), il est recalé
+// sur le plus grand ID pour ne pas reprendre un à un les codes déjà attribués.
+type codeCounter struct {
+	linkRepo repository.LinkRepository
+	mu       sync.Mutex
+	loaded   bool
+	next     uint64
+}
+
+// newCodeCounter crée un compteur qui sera initialisé depuis linkRepo au premier appel.
+func newCodeCounter(linkRepo repository.LinkRepository) *codeCounter {
+	return &codeCounter{linkRepo: linkRepo}
+}
+
+// take retourne la prochaine valeur du compteur. Avec resync (nouvelle tentative après une
+// collision), le compteur est d'abord avancé au-delà du plus grand ID de lien actuel.
+func (c *codeCounter) take(resync bool) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loaded || resync {
+		maxID, err := c.linkRepo.MaxLinkID()
+		if err != nil {
+			return 0, fmt.Errorf("erreur lors de la lecture du plus grand ID de lien pour le compteur de codes: %w", err)
+		}
+		c.next = max(c.next, uint64(maxID)+1)
+		c.loaded = true
+	}
+	value := c.next
+	c.next++
+	return value, nil
+}
+
+// encodeBase encode value dans alphabet, sur au moins width caractères (complétés par alphabet[0]).
+func encodeBase(value uint64, alphabet string, width int) string {
+	base := uint64(len(alphabet))
+	var digits []byte
+	for value > 0 || len(digits) < width {
+		digits = append(digits, alphabet[value%base])
+		value /= base
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
+
+// sequentialGenerator encode un compteur en base62. Le compteur démarre à 62^(length-1),
+// de sorte que les premiers codes aient déjà length caractères ; la longueur croît ensuite d'elle-même.
+type sequentialGenerator struct {
+	counter *codeCounter
+	length  int
+}
+
+// Generate implémente CodeGenerator. Une collision (avec un alias, par exemple) passe à la valeur suivante,
+// après avoir recalé le compteur sur les liens créés entre-temps.
+func (g *sequentialGenerator) Generate(attempt int) (string, error) {
+	value, err := g.counter.take(attempt > 0)
+	if err != nil {
+		return "", err
+	}
+	return encodeBase(pow62(g.length-1)+value-1, charset, 1), nil
+}
+
+// hashidsGenerator brouille un compteur pour produire des codes de longueur fixe qui ne révèlent
+// ni l'ordre de création ni le nombre de liens. Pour une longueur L, la valeur n du compteur est
+// transformée par la bijection n -> (n*P + S) mod 62^L, puis encodée dans un alphabet mélangé :
+// deux valeurs distinctes donnent toujours deux codes distincts. P, S et le mélange dépendent du sel.
+// Lorsque le compteur dépasse 62^L, les codes passent à L+1 caractères.
+type hashidsGenerator struct {
+	counter    *codeCounter
+	length     int
+	alphabet   string // charset mélangé selon le sel
+	multiplier uint64 // P : premier avec 62^L (impair et non multiple de 31)
+	increment  uint64 // S
+}
+
+// newHashidsGenerator dérive les paramètres du brouillage à partir du sel.
+func newHashidsGenerator(counter *codeCounter, length int, salt string) *hashidsGenerator {
+	sum := sha256.Sum256([]byte("urlshortener:" + salt))
+	multiplier := binary.BigEndian.Uint64(sum[0:8]) | 1
+	for multiplier%31 == 0 {
+		multiplier += 2
+	}
+
+	alphabet := []byte(charset)
+	shuffler := mathrand.New(mathrand.NewPCG(binary.BigEndian.Uint64(sum[16:24]), binary.BigEndian.Uint64(sum[24:32])))
+	shuffler.Shuffle(len(alphabet), func(i, j int) { alphabet[i], alphabet[j] = alphabet[j], alphabet[i] })
+
+	return &hashidsGenerator{
+		counter:    counter,
+		length:     length,
+		alphabet:   string(alphabet),
+		multiplier: multiplier,
+		increment:  binary.BigEndian.Uint64(sum[8:16]),
+	}
+}
+
+// Generate implémente CodeGenerator.
+func (g *hashidsGenerator) Generate(attempt int) (string, error) {
+	value, err := g.counter.take(attempt > 0)
+	if err != nil {
+		return "", err
+	}
+
+	// Plus petite longueur (au moins g.length) dont l'espace contient la valeur
+	length := g.length
+	space := pow62(length)
+	for value >= space {
+		if length == maxCounterCodeLength {
+			return "", fmt.Errorf("compteur de codes épuisé (%d)", value)
+		}
+		length++
+		space = pow62(length)
+	}
+
+	hi, lo := bits.Mul64(value, g.multiplier%space)
+	lo, carry := bits.Add64(lo, g.increment%space, 0)
+	hi += carry
+	mixed := bits.Rem64(hi, lo, space)
+	return encodeBase(mixed, g.alphabet, length), nil
+}
+
+// pow62 retourne 62^n.
+func pow62(n int) uint64 {
+	result := uint64(1)
+	for i := 0; i < n; i++ {
+		result *= uint64(len(charset))
+	}
+	return result
+}
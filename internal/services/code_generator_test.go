@@ -0,0 +1,230 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// maxIDRepository est un LinkRepository dont seul MaxLinkID est utilisé (par le compteur de codes).
+type maxIDRepository struct {
+	repository.LinkRepository
+	maxID uint
+}
+
+func (r *maxIDRepository) MaxLinkID() (uint, error) { return r.maxID, nil }
+
+// newTestCodeGenerator crée un générateur dont le compteur reprend après maxID.
+func newTestCodeGenerator(t *testing.T, opts CodeGeneratorOptions, maxID uint) (CodeGenerator, *maxIDRepository) {
+	t.Helper()
+	repo := &maxIDRepository{maxID: maxID}
+	codeGen, err := NewCodeGenerator(opts, repo)
+	if err != nil {
+		t.Fatalf("NewCodeGenerator(%+v): %v", opts, err)
+	}
+	return codeGen, repo
+}
+
+// generate appelle Generate (première tentative) et échoue en cas d'erreur.
+func generate(t *testing.T, codeGen CodeGenerator) string {
+	t.Helper()
+	code, err := codeGen.Generate(0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	return code
+}
+
+func TestHashidsCodesAreDistinctWithFixedLength(t *testing.T) {
+	// Longueur 2 : l'espace (62^2 = 3844 codes) est parcouru entièrement, compteur de 1 à 3843.
+	codeGen, _ := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategyHashids, Length: 2, Salt: "s3cret"}, 0)
+
+	seen := make(map[string]uint64)
+	previous, samePrefix := "", 0
+	for value := uint64(1); value < pow62(2); value++ {
+		code := generate(t, codeGen)
+		if len(code) != 2 {
+			t.Fatalf("valeur %d : code %q de longueur %d, attendu 2", value, code, len(code))
+		}
+		if strings.Trim(code, charset) != "" {
+			t.Fatalf("valeur %d : code %q hors de l'alphabet base62", value, code)
+		}
+		if other, ok := seen[code]; ok {
+			t.Fatalf("code %q attribué aux valeurs %d et %d", code, other, value)
+		}
+		seen[code] = value
+		if previous != "" && code[0] == previous[0] {
+			samePrefix++
+		}
+		previous = code
+	}
+	// Un compteur simplement encodé garderait le premier caractère 61 fois sur 62.
+	if samePrefix > len(seen)/4 {
+		t.Errorf("%d codes consécutifs sur %d partagent leur premier caractère : l'ordre est apparent", samePrefix, len(seen))
+	}
+
+	// Au-delà de 62^2, les codes passent à 3 caractères.
+	if code := generate(t, codeGen); len(code) != 3 {
+		t.Errorf("valeur %d : code %q, attendu 3 caractères", pow62(2), code)
+	}
+}
+
+func TestHashidsLengthGrowsPastSpace(t *testing.T) {
+	// Le compteur reprend juste avant la fin de l'espace des codes de 4 caractères.
+	codeGen, _ := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategyHashids, Length: 4}, uint(pow62(4)-2))
+
+	if code := generate(t, codeGen); len(code) != 4 {
+		t.Errorf("valeur 62^4-1 : code %q, attendu 4 caractères", code)
+	}
+	if code := generate(t, codeGen); len(code) != 5 {
+		t.Errorf("valeur 62^4 : code %q, attendu 5 caractères", code)
+	}
+}
+
+func TestHashidsDependsOnSalt(t *testing.T) {
+	codes := func(salt string) string {
+		codeGen, _ := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategyHashids, Length: 6, Salt: salt}, 0)
+		var all []string
+		for i := 0; i < 5; i++ {
+			all = append(all, generate(t, codeGen))
+		}
+		return strings.Join(all, ",")
+	}
+
+	if codes("a") != codes("a") {
+		t.Error("même sel, codes différents : les codes doivent être reproductibles")
+	}
+	if codes("a") == codes("b") {
+		t.Error("sels différents, mêmes codes")
+	}
+}
+
+func TestSequentialCodesStartAtLength(t *testing.T) {
+	for _, length := range []int{1, 3, 6} {
+		codeGen, _ := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategySequential, Length: length}, 0)
+
+		// Sans lien existant, le compteur démarre à 1 et le premier code encode 62^(L-1) :
+		// "b" suivi de L-1 "a" dans l'alphabet base62.
+		want := "b" + strings.Repeat("a", length-1)
+		if code := generate(t, codeGen); code != want {
+			t.Errorf("longueur %d : premier code %q, attendu %q", length, code, want)
+		}
+		if code := generate(t, codeGen); len(code) != length {
+			t.Errorf("longueur %d : second code %q", length, code)
+		}
+	}
+}
+
+func TestSequentialCodesAreConsecutive(t *testing.T) {
+	// Le compteur reprend après le lien 60 : les codes de longueur 2 sont "b" + charset[60], puis "b9", "ca".
+	codeGen, _ := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategySequential, Length: 2}, 60)
+
+	for _, want := range []string{"b8", "b9", "ca"} {
+		if code := generate(t, codeGen); code != want {
+			t.Errorf("code %q, attendu %q", code, want)
+		}
+	}
+}
+
+func TestSequentialLengthGrows(t *testing.T) {
+	// Les codes de 2 caractères partent de 62 : le lien 62^2-62 est le dernier de cette longueur.
+	codeGen, _ := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategySequential, Length: 2}, uint(pow62(2)-63))
+
+	if code := generate(t, codeGen); code != "99" {
+		t.Errorf("code %q, attendu le dernier code de 2 caractères \"99\"", code)
+	}
+	if code := generate(t, codeGen); code != "baa" {
+		t.Errorf("code %q, attendu \"baa\"", code)
+	}
+}
+
+func TestCounterResyncsAfterCollision(t *testing.T) {
+	codeGen, repo := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategySequential, Length: 2}, 0)
+
+	if code := generate(t, codeGen); code != "ba" {
+		t.Fatalf("premier code %q, attendu \"ba\"", code)
+	}
+	// Un autre processus crée des liens jusqu'à l'ID 11 ; une première tentative ne relit pas la base.
+	repo.maxID = 11
+	if code := generate(t, codeGen); code != "bb" {
+		t.Errorf("code %q, attendu \"bb\" sans recalage", code)
+	}
+	// Après une collision, le compteur reprend après le plus grand ID.
+	code, err := codeGen.Generate(1)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if code != "bl" {
+		t.Errorf("code %q après collision, attendu \"bl\" (lien 12)", code)
+	}
+}
+
+func TestRandomAndPronounceableCodes(t *testing.T) {
+	random, _ := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategyRandom, Length: 5, Alphabet: "xyz"}, 0)
+	for attempt, want := range []int{5, 5, 6, 6, 7} {
+		code, err := random.Generate(attempt)
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		if len(code) != want || strings.Trim(code, "xyz") != "" {
+			t.Errorf("tentative %d : code %q, attendu %d caractères parmi \"xyz\"", attempt, code, want)
+		}
+	}
+
+	pronounceable, _ := newTestCodeGenerator(t, CodeGeneratorOptions{Strategy: CodeStrategyPronounceable, Length: 8}, 0)
+	code := generate(t, pronounceable)
+	if len(code) != 8 {
+		t.Fatalf("code %q, attendu 8 caractères", code)
+	}
+	for i := 0; i < len(code); i++ {
+		letters := pronounceableConsonants
+		if i%2 == 1 {
+			letters = pronounceableVowels
+		}
+		if !strings.ContainsRune(letters, rune(code[i])) {
+			t.Errorf("code %q : caractère %d %q hors de %q", code, i, code[i], letters)
+		}
+	}
+}
+
+func TestValidateAlphabet(t *testing.T) {
+	tests := []struct {
+		alphabet string
+		valid    bool
+	}{
+		{charset, true},
+		{UnambiguousAlphabet, true},
+		{"ab", true},
+		{"a-b_c", true},
+		{"", false},
+		{"a", false},
+		{"aba", false},
+		{"abcdefA0123a", false},
+		{"--", false},
+		{"ab/", false},
+		{"ab c", false},
+		{"abé", false},
+	}
+	for _, tt := range tests {
+		err := validateAlphabet(tt.alphabet)
+		if (err == nil) != tt.valid {
+			t.Errorf("validateAlphabet(%q) = %v, attendu valide=%v", tt.alphabet, err, tt.valid)
+		}
+	}
+}
+
+func TestNewCodeGeneratorRejectsInvalidOptions(t *testing.T) {
+	repo := &maxIDRepository{}
+	for _, opts := range []CodeGeneratorOptions{
+		{Strategy: "uuid"},
+		{Strategy: CodeStrategyRandom, Length: maxCodeLength + 1},
+		{Strategy: CodeStrategyRandom, Alphabet: "aab"},
+		{Strategy: CodeStrategySequential, Length: maxCounterCodeLength + 1},
+		{Strategy: CodeStrategyHashids, Length: maxCounterCodeLength + 1},
+	} {
+		if _, err := NewCodeGenerator(opts, repo); err == nil {
+			t.Errorf("NewCodeGenerator(%+v) : options invalides acceptées", opts)
+		}
+	}
+}
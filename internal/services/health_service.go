@@ -0,0 +1,117 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Bornes du nombre de vérifications retournées par GetLinkHealth.
+const (
+	DefaultHealthChecksLimit = 50
+	MaxHealthChecksLimit     = 500
+)
+
+// HealthService fournit l'historique de disponibilité des destinations, enregistré par le moniteur d'URLs.
+type HealthService struct {
+	checkRepo repository.LinkCheckRepository
+}
+
+// NewHealthService crée et retourne une nouvelle instance de HealthService.
+func NewHealthService(checkRepo repository.LinkCheckRepository) *HealthService {
+	return &HealthService{
+		checkRepo: checkRepo,
+	}
+}
+
+// HealthCheckEntry est une vérification de la destination d'un lien.
+type HealthCheckEntry struct {
+	CheckedAt     time.Time         `json:"checked_at"`
+	Healthy       bool              `json:"healthy"`
+	Method        string            `json:"method,omitempty"`
+	StatusCode    int               `json:"status_code,omitempty"`
+	LatencyMs     int64             `json:"latency_ms"`
+	FailureKind   string            `json:"failure_kind,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	FinalURL      string            `json:"final_url,omitempty"`
+	RedirectChain []json.RawMessage `json:"redirect_chain,omitempty"`
+	TLS           *HealthCheckTLS   `json:"tls,omitempty"` // Certificat examiné (nil pour une URL http ou injoignable)
+}
+
+// HealthCheckTLS décrit le certificat TLS examiné lors d'une vérification.
+type HealthCheckTLS struct {
+	Status        string     `json:"status"` // valid, expiring ou invalid
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Issuer        string     `json:"issuer,omitempty"`
+	HostnameValid *bool      `json:"hostname_valid,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// LinkHealth est l'historique de disponibilité d'un lien sur une période.
+type LinkHealth struct {
+	Total         int64              `json:"total_checks"`   // Nombre de vérifications sur la période
+	Failures      int64              `json:"failed_checks"`  // Nombre de vérifications en échec
+	UptimePercent float64            `json:"uptime_percent"` // Part des vérifications réussies (100 si aucune vérification)
+	Checks        []HealthCheckEntry `json:"checks"`         // Vérifications les plus récentes, de la plus récente à la plus ancienne
+}
+
+// GetLinkHealth retourne le résumé et les vérifications les plus récentes d'un lien sur la période du filtre.
+// filter.Limit vaut DefaultHealthChecksLimit s'il est nul et est borné à MaxHealthChecksLimit.
+func (s *HealthService) GetLinkHealth(filter repository.LinkCheckFilter) (*LinkHealth, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHealthChecksLimit
+	}
+	if filter.Limit > MaxHealthChecksLimit {
+		filter.Limit = MaxHealthChecksLimit
+	}
+
+	summary, err := s.checkRepo.SummarizeChecks(filter)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du résumé des vérifications: %w", err)
+	}
+	checks, err := s.checkRepo.ListChecks(filter)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des vérifications: %w", err)
+	}
+
+	health := &LinkHealth{
+		Total:         summary.Total,
+		Failures:      summary.Failures,
+		UptimePercent: 100,
+		Checks:        make([]HealthCheckEntry, 0, len(checks)),
+	}
+	if summary.Total > 0 {
+		uptime := float64(summary.Total-summary.Failures) / float64(summary.Total) * 100
+		health.UptimePercent = math.Round(uptime*100) / 100
+	}
+	for _, check := range checks {
+		entry := HealthCheckEntry{
+			CheckedAt:   check.CheckedAt,
+			Healthy:     check.Healthy,
+			Method:      check.Method,
+			StatusCode:  check.StatusCode,
+			LatencyMs:   check.LatencyMs,
+			FailureKind: check.FailureKind,
+			Error:       check.Error,
+			FinalURL:    check.FinalURL,
+		}
+		if check.RedirectChain != "" {
+			// La chaîne est stockée en JSON par le moniteur ; une valeur illisible est simplement omise.
+			_ = json.Unmarshal([]byte(check.RedirectChain), &entry.RedirectChain)
+		}
+		if check.TLSStatus != "" {
+			entry.TLS = &HealthCheckTLS{
+				Status:        check.TLSStatus,
+				ExpiresAt:     check.TLSExpiresAt,
+				Issuer:        check.TLSIssuer,
+				HostnameValid: check.TLSHostnameValid,
+				Error:         check.TLSError,
+			}
+		}
+		health.Checks = append(health.Checks, entry)
+	}
+	return health, nil
+}
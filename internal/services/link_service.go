@@ -1,11 +1,13 @@
 package services
 
 import (
-	"crypto/rand"
 	"errors"
 	"fmt"
 	"log"
-	"math/big"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm" // Nécessaire pour la gestion spécifique de gorm.ErrRecordNotFound
@@ -15,87 +17,269 @@ import (
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le package repository
 )
 
-// Définition du jeu de caractères pour la génération des codes courts.
+// Définition du jeu de caractères (base62) des codes courts générés.
 const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
+// Contraintes appliquées aux alias personnalisés.
+const (
+	aliasMinLength = 3
+	aliasMaxLength = 32
+)
+
+// aliasPattern définit le jeu de caractères autorisé pour un alias :
+// lettres, chiffres, tirets et underscores (pas de '/' ni de caractères à encoder dans l'URL).
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedAliases liste les chemins déjà utilisés par le routeur (ou susceptibles de l'être).
+// Un alias ne doit pas pouvoir masquer une route de l'application.
+var reservedAliases = map[string]bool{
+	"health":  true,
+	"api":     true,
+	"admin":   true,
+	"static":  true,
+	"metrics": true,
+}
+
+// CreateLinkOptions regroupe les paramètres optionnels de la création d'un lien.
+// ExpiresAt et TTL sont mutuellement exclusifs : l'un fixe une date absolue, l'autre une durée relative.
+type CreateLinkOptions struct {
+	Alias     string        // Alias personnalisé (vide = code court généré aléatoirement)
+	ExpiresAt *time.Time    // Date d'expiration absolue (optionnelle)
+	TTL       time.Duration // Durée de vie relative à la création (0 = pas de TTL)
+	OwnerID   *uint         // Utilisateur propriétaire du lien (nil = sans propriétaire)
+
+	// Dedupe retourne le lien existant du même propriétaire pour la même URL normalisée plutôt que
+	// d'en créer un nouveau. Ignoré si un alias ou une expiration est demandé : le lien existant
+	// n'aurait pas le code ou la durée de vie voulus.
+	// Les créations simultanées d'un même LinkService sont sérialisées par propriétaire et URL ;
+	// entre processus distincts (serveur et CLI, plusieurs serveurs), deux liens peuvent encore être
+	// créés pour la même URL, aucune contrainte d'unicité ne portant sur (owner_id, url_hash).
+	Dedupe bool
+}
+
+// resolveExpiration calcule la date d'expiration effective à partir des options.
+// Elle retourne nil si le lien ne doit jamais expirer.
+func (o CreateLinkOptions) resolveExpiration(now time.Time) (*time.Time, error) {
+	if o.ExpiresAt != nil && o.TTL != 0 {
+		return nil, &customerrors.ErrInvalidExpiration{Reason: "expires_at et ttl ne peuvent pas être fournis ensemble"}
+	}
+	if o.TTL < 0 {
+		return nil, &customerrors.ErrInvalidExpiration{Reason: "la durée de vie doit être positive"}
+	}
+	if o.TTL > 0 {
+		expiresAt := now.Add(o.TTL).UTC()
+		return &expiresAt, nil
+	}
+	if o.ExpiresAt != nil {
+		if !o.ExpiresAt.After(now) {
+			return nil, &customerrors.ErrInvalidExpiration{Reason: "la date d'expiration doit être dans le futur"}
+		}
+		expiresAt := o.ExpiresAt.UTC()
+		return &expiresAt, nil
+	}
+	return nil, nil
+}
+
 // LinkService est une structure qui fournit des méthodes pour la logique métier des liens.
 // Elle détient linkRepo qui est une référence vers une interface LinkRepository.
 // IMPORTANT : Le champ doit être du type de l'interface (non-pointeur).
 type LinkService struct {
-	linkRepo repository.LinkRepository
+	linkRepo    repository.LinkRepository
+	codeGen     CodeGenerator // Stratégie de génération des codes courts
+	dedupeLocks keyedMutex    // Sérialise les créations dédupliquées d'une même URL pour un même propriétaire
+}
+
+// keyedMutex fournit un verrou par clé, supprimé dès qu'il n'est plus utilisé.
+// Sa valeur zéro est prête à l'emploi.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock est le verrou d'une clé et le nombre de goroutines qui le détiennent ou l'attendent.
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock verrouille key et retourne la fonction qui la déverrouille.
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
 }
 
 
 // NewLinkService crée et retourne une nouvelle instance de LinkService.
-func NewLinkService(linkRepo repository.LinkRepository) *LinkService {
+// codeGen produit les codes courts des nouveaux liens ; s'il est nil (commandes qui ne créent
+// pas de liens), les codes sont tirés au hasard en base62 sur DefaultCodeLength caractères.
+func NewLinkService(linkRepo repository.LinkRepository, codeGen CodeGenerator) *LinkService {
+	if codeGen == nil {
+		codeGen = NewRandomCodeGenerator(charset, DefaultCodeLength)
+	}
 	return &LinkService{
 		linkRepo: linkRepo,
+		codeGen:  codeGen,
 	}
 }
 
-// GenerateShortCode génère un code court aléatoire d'une longueur spécifiée.
-// Il utilise le package 'crypto/rand' pour éviter la prévisibilité.
-func (s *LinkService) GenerateShortCode(length int) (string, error) {
-	result := make([]byte, length)
-	charsetLen := big.NewInt(int64(len(charset)))
-	
-	for i := 0; i < length; i++ {
-		// Génère un nombre aléatoire sécurisé entre 0 et len(charset)-1
-		randomIndex, err := rand.Int(rand.Reader, charsetLen)
-		if err != nil {
-			return "", fmt.Errorf("erreur lors de la génération du code aléatoire: %w", err)
+// ValidateAlias vérifie qu'un alias personnalisé respecte le jeu de caractères,
+// les bornes de longueur et qu'il ne correspond pas à un chemin réservé.
+func ValidateAlias(alias string) error {
+	if len(alias) < aliasMinLength || len(alias) > aliasMaxLength {
+		return &customerrors.ErrInvalidAlias{
+			Alias:  alias,
+			Reason: fmt.Sprintf("la longueur doit être comprise entre %d et %d caractères", aliasMinLength, aliasMaxLength),
 		}
-		result[i] = charset[randomIndex.Int64()]
 	}
-	
-	return string(result), nil
+	if !aliasPattern.MatchString(alias) {
+		return &customerrors.ErrInvalidAlias{
+			Alias:  alias,
+			Reason: "seuls les lettres, chiffres, '-' et '_' sont autorisés",
+		}
+	}
+	if reservedAliases[strings.ToLower(alias)] {
+		return &customerrors.ErrInvalidAlias{Alias: alias, Reason: "ce chemin est réservé"}
+	}
+	return nil
 }
 
+// CreateLink crée un nouveau lien raccourci, avec une date d'expiration optionnelle.
+// Si un alias est fourni dans les options, il est validé puis utilisé tel quel comme code court.
+// Sinon, il génère un code court unique. Le lien est ensuite persisté dans la base de données.
+// Avec opts.Dedupe, un lien existant pour la même destination est retourné à la place :
+// le booléen retourné indique si le lien a été créé (false pour un lien existant).
+func (s *LinkService) CreateLink(longURL string, opts CreateLinkOptions) (*models.Link, bool, error) {
+	if err := ValidateLongURL(longURL); err != nil {
+		return nil, false, err
+	}
 
-// CreateLink crée un nouveau lien raccourci.
-// Il génère un code court unique, puis persiste le lien dans la base de données.
-func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
-	var shortCode string
-	const maxRetries = 5
-
-	for i := 0; i < maxRetries; i++ {
-		code, err := s.GenerateShortCode(6)
-		if err != nil {
-			return nil, fmt.Errorf("erreur lors de la génération du code court: %w", err)
-		}
-
-		_, err = s.linkRepo.GetLinkByShortCode(code)
-		
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				shortCode = code
-				break
-			}
-			return nil, fmt.Errorf("database error checking short code uniqueness: %w", err)
-		}
+	now := time.Now()
+	expiresAt, err := opts.resolveExpiration(now)
+	if err != nil {
+		return nil, false, err
+	}
 
-		log.Printf("Short code '%s' already exists, retrying generation (%d/%d)...", code, i+1, maxRetries)
+	urlHash, err := URLHash(longURL)
+	if err != nil {
+		return nil, false, &customerrors.ErrInvalidURL{URL: longURL, Reason: err.Error()}
 	}
 
-	if shortCode == "" {
-		// Utilisation d'une erreur personnalisée pour indiquer l'échec après plusieurs tentatives
-		return nil, &customerrors.ErrMaxRetriesExceeded{
-			MaxRetries: maxRetries,
-			Operation:  "génération de code court unique",
+	if opts.Dedupe && opts.Alias == "" && expiresAt == nil {
+		// Recherche et insertion sous le même verrou : deux créations simultanées de la même URL
+		// ne peuvent pas toutes deux constater l'absence de lien puis en insérer un.
+		unlock := s.dedupeLocks.lock(dedupeKey(opts.OwnerID, urlHash))
+		defer unlock()
+
+		existing, err := s.linkRepo.FindActiveLinkByURLHash(opts.OwnerID, urlHash)
+		if err == nil {
+			return existing, false, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, fmt.Errorf("erreur lors de la recherche d'un lien existant: %w", err)
 		}
 	}
 
 	link := &models.Link{
 		LongURL:   longURL,
-		ShortCode: shortCode,
-		CreatedAt: time.Now(),
+		URLHash:   urlHash,
+		CreatedAt: now.UTC(), // En UTC, comme les bornes des filtres par date de création
+		ExpiresAt: expiresAt,
+		OwnerID:   opts.OwnerID,
+	}
+
+	if opts.Alias != "" {
+		err = s.insertWithAlias(link, opts.Alias)
+	} else {
+		err = s.insertWithGeneratedCode(link)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return link, true, nil
+}
+
+// dedupeKey identifie un propriétaire (nil = sans propriétaire) et une URL normalisée.
+func dedupeKey(ownerID *uint, urlHash string) string {
+	if ownerID == nil {
+		return "-:" + urlHash
 	}
+	return fmt.Sprintf("%d:%s", *ownerID, urlHash)
+}
 
+// insertWithAlias valide un alias personnalisé et insère le lien sous ce code.
+// La disponibilité de l'alias est garantie par la contrainte d'unicité de short_code,
+// et non par une vérification préalable qu'une création simultanée pourrait devancer.
+func (s *LinkService) insertWithAlias(link *models.Link, alias string) error {
+	if err := ValidateAlias(alias); err != nil {
+		return err
+	}
+
+	link.ShortCode = alias
 	if err := s.linkRepo.CreateLink(link); err != nil {
-		return nil, fmt.Errorf("erreur lors de la création du lien: %w", err)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return &customerrors.ErrAliasTaken{Alias: alias}
+		}
+		return fmt.Errorf("erreur lors de la création du lien: %w", err)
 	}
+	return nil
+}
 
-	return link, nil
+// maxCodeAttempts est le nombre maximum de codes générés pour un même lien.
+const maxCodeAttempts = 8
+
+// insertWithGeneratedCode insère le lien sous un code produit par le générateur configuré.
+// Une violation de la contrainte d'unicité (code déjà pris, y compris par un lien supprimé ou par une
+// création simultanée) est une collision : un nouveau code est généré, le générateur l'allongeant
+// si les collisions s'accumulent. Après maxCodeAttempts collisions, retourne ErrCodeCollision.
+func (s *LinkService) insertWithGeneratedCode(link *models.Link) error {
+	var collision *customerrors.ErrCodeCollision
+
+	for attempt := 0; attempt < maxCodeAttempts; attempt++ {
+		code, err := s.codeGen.Generate(attempt)
+		if err != nil {
+			return fmt.Errorf("erreur lors de la génération du code court: %w", err)
+		}
+		collision = &customerrors.ErrCodeCollision{Code: code, Attempts: attempt + 1}
+
+		// Un code généré ne doit pas plus qu'un alias masquer une route de l'application
+		if reservedAliases[strings.ToLower(code)] {
+			continue
+		}
+
+		link.ShortCode = code
+		err = s.linkRepo.CreateLink(link)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("erreur lors de la création du lien: %w", err)
+		}
+
+		log.Printf("Short code '%s' already exists, retrying generation (%d/%d)...", code, attempt+1, maxCodeAttempts)
+	}
+
+	return collision
 }
 
 // GetLinkByShortCode récupère un lien via son code court.
@@ -112,9 +296,249 @@ func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error)
 	return link, nil
 }
 
+// GetLinkForRedirect récupère un lien destiné à une redirection.
+// Contrairement à GetLinkByShortCode, il retourne ErrLinkExpired si le lien a expiré.
+func (s *LinkService) GetLinkForRedirect(shortCode string) (*models.Link, error) {
+	link, err := s.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if link.IsExpired(time.Now()) {
+		return nil, &customerrors.ErrLinkExpired{ShortCode: shortCode}
+	}
+	return link, nil
+}
+
+// ValidateLongURL vérifie qu'une URL longue est absolue et utilise le schéma http ou https.
+func ValidateLongURL(longURL string) error {
+	parsed, err := url.ParseRequestURI(longURL)
+	if err != nil {
+		return &customerrors.ErrInvalidURL{URL: longURL, Reason: err.Error()}
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return &customerrors.ErrInvalidURL{URL: longURL, Reason: "le schéma doit être http ou https"}
+	}
+	if parsed.Host == "" {
+		return &customerrors.ErrInvalidURL{URL: longURL, Reason: "l'hôte est manquant"}
+	}
+	return nil
+}
+
+// LinkUpdate regroupe les modifications d'un lien existant. Un champ nil n'est pas modifié.
+type LinkUpdate struct {
+	LongURL     *string // Nouvelle URL de destination
+	DownPolicy  *string // Politique si la destination est inaccessible (voir models.DownPolicy*)
+	FallbackURL *string // URL de secours de la politique "fallback" ("" pour la supprimer)
+}
+
+// IsEmpty indique si la mise à jour ne modifie aucun champ.
+func (u LinkUpdate) IsEmpty() bool {
+	return u.LongURL == nil && u.DownPolicy == nil && u.FallbackURL == nil
+}
+
+// UpdateLink modifie un lien existant : URL de destination, politique appliquée lorsque
+// la destination est inaccessible et URL de secours.
+// Changer de destination remet l'état de santé à "unknown" jusqu'à la prochaine vérification.
+// Le code court, la date de création et l'historique des clics sont conservés.
+func (s *LinkService) UpdateLink(shortCode string, update LinkUpdate) (*models.Link, error) {
+	if update.LongURL != nil {
+		if err := ValidateLongURL(*update.LongURL); err != nil {
+			return nil, err
+		}
+	}
+	if update.DownPolicy != nil && !models.IsValidDownPolicy(*update.DownPolicy) {
+		return nil, &customerrors.ErrInvalidDownPolicy{
+			Policy: *update.DownPolicy,
+			Reason: "valeurs possibles : redirect, fallback, unavailable",
+		}
+	}
+	if update.FallbackURL != nil && *update.FallbackURL != "" {
+		if err := ValidateLongURL(*update.FallbackURL); err != nil {
+			return nil, err
+		}
+	}
+
+	link, err := s.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	resetHealth := false
+	if update.LongURL != nil {
+		urlHash, err := URLHash(*update.LongURL)
+		if err != nil {
+			return nil, &customerrors.ErrInvalidURL{URL: *update.LongURL, Reason: err.Error()}
+		}
+		if *update.LongURL != link.LongURL {
+			// Nouvelle destination : l'état de santé de l'ancienne ne la concerne pas, et une
+			// politique "fallback" ou "unavailable" ne doit pas s'appliquer avant une nouvelle vérification.
+			resetHealth = true
+			link.HealthStatus = models.HealthUnknown
+			link.LastCheckedAt = nil
+			link.FinalURL = ""
+			link.TLSStatus = ""
+			link.TLSExpiresAt = nil
+			link.TLSIssuer = ""
+			link.TLSHostnameValid = nil
+		}
+		link.LongURL = *update.LongURL
+		link.URLHash = urlHash
+	}
+	if update.DownPolicy != nil {
+		link.DownPolicy = *update.DownPolicy
+	}
+	if update.FallbackURL != nil {
+		link.FallbackURL = *update.FallbackURL
+	}
+	if link.DownPolicy == models.DownPolicyFallback && link.FallbackURL == "" {
+		return nil, &customerrors.ErrInvalidDownPolicy{Policy: link.DownPolicy, Reason: "une URL de secours est requise"}
+	}
+
+	if err := s.linkRepo.UpdateLink(link, resetHealth); err != nil {
+		return nil, fmt.Errorf("erreur lors de la mise à jour du lien: %w", err)
+	}
+	return link, nil
+}
+
+// ReassignLink transfère un lien à l'utilisateur ownerID (nil = lien sans propriétaire).
+func (s *LinkService) ReassignLink(shortCode string, ownerID *uint) (*models.Link, error) {
+	link, err := s.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.linkRepo.SetOwner(link.ID, ownerID); err != nil {
+		return nil, fmt.Errorf("erreur lors du transfert du lien: %w", err)
+	}
+	link.OwnerID = ownerID
+	return link, nil
+}
+
+// ReassignLinks transfère tous les liens de fromOwnerID à toOwnerID (nil = sans propriétaire)
+// et retourne le nombre de liens transférés.
+func (s *LinkService) ReassignLinks(fromOwnerID, toOwnerID *uint) (int64, error) {
+	count, err := s.linkRepo.ReassignLinks(fromOwnerID, toOwnerID)
+	if err != nil {
+		return 0, fmt.Errorf("erreur lors du transfert des liens: %w", err)
+	}
+	return count, nil
+}
+
+// BackfillURLHashes calcule l'empreinte de l'URL normalisée des liens qui n'en ont pas
+// (créés avant l'introduction de la déduplication) et retourne le nombre de liens complétés.
+func (s *LinkService) BackfillURLHashes() (int, error) {
+	const batchSize = 500
+
+	filled := 0
+	var afterID uint
+	for {
+		links, err := s.linkRepo.GetLinksBatch(afterID, batchSize)
+		if err != nil {
+			return filled, fmt.Errorf("erreur lors du parcours des liens: %w", err)
+		}
+		for _, link := range links {
+			if link.URLHash != "" {
+				continue
+			}
+			urlHash, err := URLHash(link.LongURL)
+			if err != nil {
+				log.Printf("Warning: could not hash URL of link %s: %v", link.ShortCode, err)
+				continue
+			}
+			if err := s.linkRepo.SetURLHash(link.ID, urlHash); err != nil {
+				return filled, err
+			}
+			filled++
+		}
+		if len(links) < batchSize {
+			return filled, nil
+		}
+		afterID = links[len(links)-1].ID
+	}
+}
+
+// MaxHealthThreshold est la valeur maximale d'un seuil d'échecs ou de succès consécutifs propre à un lien.
+const MaxHealthThreshold = 100
+
+// SetHealthThresholds définit les seuils d'échecs et de succès consécutifs utilisés par le moniteur
+// pour ce lien. Un seuil nil rétablit la valeur globale de la configuration.
+func (s *LinkService) SetHealthThresholds(shortCode string, failure, recovery *int) (*models.Link, error) {
+	if err := validateHealthThreshold("failure_threshold", failure); err != nil {
+		return nil, err
+	}
+	if err := validateHealthThreshold("recovery_threshold", recovery); err != nil {
+		return nil, err
+	}
+
+	link, err := s.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.linkRepo.SetHealthThresholds(link.ID, failure, recovery); err != nil {
+		return nil, fmt.Errorf("erreur lors de la mise à jour des seuils du lien: %w", err)
+	}
+	link.FailureThreshold = failure
+	link.RecoveryThreshold = recovery
+	return link, nil
+}
+
+// validateHealthThreshold vérifie qu'un seuil, s'il est fourni, est compris entre 1 et MaxHealthThreshold.
+func validateHealthThreshold(param string, value *int) error {
+	if value != nil && (*value < 1 || *value > MaxHealthThreshold) {
+		return &customerrors.ErrInvalidQuery{Param: param, Reason: fmt.Sprintf("doit être compris entre 1 et %d", MaxHealthThreshold)}
+	}
+	return nil
+}
+
+// DeleteLink supprime un lien via son code court.
+// La suppression est douce : le lien ne redirige plus mais ses clics restent consultables en base.
+func (s *LinkService) DeleteLink(shortCode string) error {
+	link, err := s.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return err
+	}
+
+	if err := s.linkRepo.DeleteLink(link.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &customerrors.ErrLinkNotFound{ShortCode: shortCode}
+		}
+		return fmt.Errorf("erreur lors de la suppression du lien: %w", err)
+	}
+	return nil
+}
+
+// ListLinks récupère une page de liens filtrée et triée, avec le nombre de clics de chaque lien.
+// Les erreurs de paramètres (curseur, tri...) sont retournées sous forme de ErrInvalidQuery.
+func (s *LinkService) ListLinks(opts repository.ListLinksOptions) (*repository.LinkPage, error) {
+	page, err := s.linkRepo.ListLinks(opts)
+	if err != nil {
+		var invalidQueryErr *customerrors.ErrInvalidQuery
+		if errors.As(err, &invalidQueryErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erreur lors de la récupération de la liste des liens: %w", err)
+	}
+	return page, nil
+}
+
+// ParseDateTime interprète une date fournie par un utilisateur (paramètre d'API ou flag CLI).
+// Les formats acceptés sont RFC 3339 ("2025-01-31T14:00:00Z") et la date seule ("2025-01-31", minuit UTC).
+func ParseDateTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("format de date invalide '%s' (attendu: YYYY-MM-DD ou RFC 3339)", value)
+	}
+	return t, nil
+}
+
 // GetLinkStats récupère les statistiques pour un lien donné (nombre total de clics).
-// Il interagit avec le LinkRepository pour obtenir le lien, puis avec le ClickRepository
-func (s *LinkService) GetLinkStats(shortCode string) (*models.Link, int, error) {
+// Il interagit avec le LinkRepository pour obtenir le lien, puis avec le ClickRepository.
+// Les clics de robots ne sont comptés que si includeBots est vrai.
+func (s *LinkService) GetLinkStats(shortCode string, includeBots bool) (*models.Link, int, error) {
 	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
 	if err != nil {
 		// Si le lien n'est pas trouvé, retourner une erreur personnalisée
@@ -124,7 +548,7 @@ func (s *LinkService) GetLinkStats(shortCode string) (*models.Link, int, error)
 		return nil, 0, fmt.Errorf("erreur lors de la récupération du lien: %w", err)
 	}
 
-	count, err := s.linkRepo.CountClicksByLinkID(link.ID)
+	count, err := s.linkRepo.CountClicksByLinkID(link.ID, includeBots)
 	if err != nil {
 		return nil, 0, fmt.Errorf("erreur lors du comptage des clics: %w", err)
 	}
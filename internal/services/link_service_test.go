@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestLinkService ouvre une base SQLite dans un fichier temporaire : contrairement à une base
+// en mémoire, elle est partagée par toutes les connexions du pool, comme en production.
+func newTestLinkService(t *testing.T, codeGen CodeGenerator) (*LinkService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("ouverture de la base: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Link{}); err != nil {
+		t.Fatalf("migration: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return NewLinkService(repository.NewLinkRepository(db), codeGen), db
+}
+
+// runConcurrently lance n appels de create en parallèle et retourne leurs résultats.
+func runConcurrently(n int, create func(i int) (*models.Link, error)) ([]*models.Link, []error) {
+	links := make([]*models.Link, n)
+	errs := make([]error, n)
+
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < n; i++ {
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			start.Wait()
+			links[i], errs[i] = create(i)
+		}(i)
+	}
+	start.Done()
+	done.Wait()
+
+	return links, errs
+}
+
+func TestCreateLinkConcurrentSameAlias(t *testing.T) {
+	service, db := newTestLinkService(t, nil)
+
+	const n = 20
+	_, errs := runConcurrently(n, func(i int) (*models.Link, error) {
+		link, _, err := service.CreateLink(fmt.Sprintf("https://example.com/%d", i), CreateLinkOptions{Alias: "promo"})
+		return link, err
+	})
+
+	created := 0
+	for i, err := range errs {
+		var taken *customerrors.ErrAliasTaken
+		switch {
+		case err == nil:
+			created++
+		case errors.As(err, &taken):
+			if taken.Alias != "promo" {
+				t.Errorf("appel %d: alias %q, attendu %q", i, taken.Alias, "promo")
+			}
+		default:
+			t.Errorf("appel %d: erreur inattendue %T: %v", i, err, err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d création(s) réussie(s), attendu exactement 1", created)
+	}
+
+	var count int64
+	if err := db.Model(&models.Link{}).Where("short_code = ?", "promo").Count(&count).Error; err != nil {
+		t.Fatalf("comptage: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("%d lien(s) enregistré(s) sous l'alias, attendu 1", count)
+	}
+}
+
+func TestCreateLinkConcurrentGeneratedCodes(t *testing.T) {
+	// Deux lettres sur une longueur initiale de 1 : au plus 2+4+8+16 codes sur maxCodeAttempts
+	// tentatives, si bien que des collisions sont garanties avec plus de liens que de codes.
+	service, db := newTestLinkService(t, NewRandomCodeGenerator("ab", 1))
+
+	const n = 60
+	links, errs := runConcurrently(n, func(i int) (*models.Link, error) {
+		link, _, err := service.CreateLink(fmt.Sprintf("https://example.com/%d", i), CreateLinkOptions{})
+		return link, err
+	})
+
+	codes := make(map[string]bool)
+	collisions := 0
+	for i, err := range errs {
+		var collision *customerrors.ErrCodeCollision
+		switch {
+		case err == nil:
+			if codes[links[i].ShortCode] {
+				t.Errorf("appel %d: code %q attribué deux fois", i, links[i].ShortCode)
+			}
+			codes[links[i].ShortCode] = true
+		case errors.As(err, &collision):
+			collisions++
+		default:
+			t.Errorf("appel %d: erreur inattendue %T: %v", i, err, err)
+		}
+	}
+	if collisions == 0 {
+		t.Error("aucune collision signalée alors que l'espace de codes est saturé")
+	}
+
+	var count int64
+	if err := db.Model(&models.Link{}).Count(&count).Error; err != nil {
+		t.Fatalf("comptage: %v", err)
+	}
+	if int(count) != len(codes) {
+		t.Errorf("%d lien(s) en base pour %d création(s) réussie(s)", count, len(codes))
+	}
+}
+
+func TestCreateLinkSequentialCounterCatchesUp(t *testing.T) {
+	opts := CodeGeneratorOptions{Strategy: CodeStrategySequential, Length: 3}
+	_, db := newTestLinkService(t, nil)
+	linkRepo := repository.NewLinkRepository(db)
+
+	// Deux services sur la même base, chacun avec son compteur : le serveur et la CLI, par exemple.
+	newService := func() *LinkService {
+		codeGen, err := NewCodeGenerator(opts, linkRepo)
+		if err != nil {
+			t.Fatalf("générateur: %v", err)
+		}
+		return NewLinkService(linkRepo, codeGen)
+	}
+	server, cli := newService(), newService()
+
+	if _, _, err := server.CreateLink("https://example.com/server", CreateLinkOptions{}); err != nil {
+		t.Fatalf("création initiale: %v", err)
+	}
+	// Plus de liens que de tentatives : le compteur du serveur ne peut pas les sauter un à un.
+	for i := 0; i < 2*maxCodeAttempts; i++ {
+		if _, _, err := cli.CreateLink(fmt.Sprintf("https://example.com/cli/%d", i), CreateLinkOptions{}); err != nil {
+			t.Fatalf("création %d par la CLI: %v", i, err)
+		}
+	}
+
+	if _, _, err := server.CreateLink("https://example.com/server/2", CreateLinkOptions{}); err != nil {
+		t.Fatalf("création après les liens de la CLI: %v", err)
+	}
+}
+
+func TestCreateLinkConcurrentDedupe(t *testing.T) {
+	service, db := newTestLinkService(t, nil)
+
+	const n = 50
+	links, errs := runConcurrently(n, func(i int) (*models.Link, error) {
+		link, _, err := service.CreateLink("https://example.com/promo", CreateLinkOptions{Dedupe: true})
+		return link, err
+	})
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("appel %d: erreur inattendue: %v", i, err)
+		}
+		if links[i].ID != links[0].ID {
+			t.Errorf("appel %d: lien %d, attendu le lien %d", i, links[i].ID, links[0].ID)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&models.Link{}).Count(&count).Error; err != nil {
+		t.Fatalf("comptage: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("%d lien(s) créé(s) pour la même URL, attendu 1", count)
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// NormalizeURL retourne la forme canonique d'une URL longue, utilisée pour reconnaître deux
+// écritures d'une même destination : schéma et hôte en minuscules, port par défaut retiré,
+// chemin vide remplacé par "/", paramètres de requête triés et fragment (#...) supprimé.
+// Le chemin, sensible à la casse, est conservé tel quel.
+func NormalizeURL(longURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(longURL))
+	if err != nil {
+		return "", err
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host, port := strings.ToLower(parsed.Hostname()), parsed.Port()
+	if (parsed.Scheme == "http" && port == "80") || (parsed.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		host = "[" + host + "]" // Adresse IPv6 sans port
+	}
+	parsed.Host = host
+
+	if parsed.Path == "" && parsed.Opaque == "" {
+		parsed.Path = "/"
+	}
+	// Encode trie les paramètres par nom ; une requête illisible est conservée telle quelle
+	if query, err := url.ParseQuery(parsed.RawQuery); err == nil {
+		parsed.RawQuery = query.Encode()
+	}
+	parsed.ForceQuery = false
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	return parsed.String(), nil
+}
+
+// URLHash retourne l'empreinte SHA-256 (hexadécimale) de l'URL normalisée, stockée dans links.url_hash.
+func URLHash(longURL string) (string, error) {
+	normalized, err := NormalizeURL(longURL)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"schéma et hôte en minuscules", "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"chemin vide", "https://example.com", "https://example.com/"},
+		{"espaces autour", "  https://example.com/a  ", "https://example.com/a"},
+		{"port https par défaut", "https://example.com:443/a", "https://example.com/a"},
+		{"port http par défaut", "http://example.com:80/a", "http://example.com/a"},
+		{"port 80 en https conservé", "https://example.com:80/a", "https://example.com:80/a"},
+		{"port 443 en http conservé", "http://example.com:443/a", "http://example.com:443/a"},
+		{"port non standard", "http://Example.com:8080", "http://example.com:8080/"},
+		{"IPv6 sans port", "https://[2001:DB8::1]/a", "https://[2001:db8::1]/a"},
+		{"IPv6 avec port par défaut", "https://[2001:db8::1]:443/a", "https://[2001:db8::1]/a"},
+		{"IPv6 avec port", "http://[::1]:8080/a", "http://[::1]:8080/a"},
+		{"paramètres triés par nom", "https://example.com/?b=2&a=1&c=3", "https://example.com/?a=1&b=2&c=3"},
+		{"ordre des valeurs d'un paramètre conservé", "https://example.com/?t=2&a=1&t=1", "https://example.com/?a=1&t=2&t=1"},
+		{"paramètres encodés", "https://example.com/?q=a%20b&e=%C3%A9", "https://example.com/?e=%C3%A9&q=a+b"},
+		{"point d'interrogation seul", "https://example.com/a?", "https://example.com/a"},
+		{"fragment supprimé", "https://example.com/a#section", "https://example.com/a"},
+		{"fragment et paramètres", "https://example.com/a?b=1&a=2#top", "https://example.com/a?a=2&b=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeURL(tt.in)
+			if err != nil {
+				t.Fatalf("NormalizeURL(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeURL(%q) = %q, attendu %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLHash(t *testing.T) {
+	same := []string{
+		"https://example.com/page?b=2&a=1",
+		"HTTPS://EXAMPLE.com:443/page?a=1&b=2#intro",
+	}
+	different := []string{
+		"https://example.com/Page?a=1&b=2", // Le chemin est sensible à la casse
+		"http://example.com/page?a=1&b=2",
+		"https://example.com/page?a=1&b=3",
+	}
+
+	want, err := URLHash(same[0])
+	if err != nil {
+		t.Fatalf("URLHash: %v", err)
+	}
+	if len(want) != 64 {
+		t.Errorf("empreinte %q, attendu 64 caractères hexadécimaux", want)
+	}
+	for _, url := range same[1:] {
+		if got, _ := URLHash(url); got != want {
+			t.Errorf("URLHash(%q) différente de celle de %q", url, same[0])
+		}
+	}
+	for _, url := range different {
+		if got, _ := URLHash(url); got == want {
+			t.Errorf("URLHash(%q) identique à celle de %q", url, same[0])
+		}
+	}
+
+	if _, err := URLHash("https://exa mple.com/"); err == nil {
+		t.Error("URLHash: URL illisible acceptée")
+	}
+}
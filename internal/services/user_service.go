@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/customerrors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// userNameMaxLength est la longueur maximale d'un nom d'utilisateur (taille de la colonne users.name).
+const userNameMaxLength = 64
+
+// UserService gère les utilisateurs (ou équipes) propriétaires de liens.
+type UserService struct {
+	userRepo repository.UserRepository
+}
+
+// NewUserService crée et retourne une nouvelle instance de UserService.
+func NewUserService(userRepo repository.UserRepository) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+	}
+}
+
+// CreateUser crée un utilisateur. Le nom suit les mêmes règles de caractères qu'un alias
+// (lettres, chiffres, '-' et '_') et doit être unique.
+func (s *UserService) CreateUser(name string) (*models.User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > userNameMaxLength || !aliasPattern.MatchString(name) {
+		return nil, &customerrors.ErrInvalidQuery{
+			Param:  "name",
+			Reason: fmt.Sprintf("1 à %d caractères parmi les lettres, chiffres, '-' et '_'", userNameMaxLength),
+		}
+	}
+
+	_, err := s.userRepo.GetUserByName(name)
+	if err == nil {
+		return nil, &customerrors.ErrUserExists{Name: name}
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("erreur lors de la vérification du nom d'utilisateur: %w", err)
+	}
+
+	user := &models.User{Name: name}
+	if err := s.userRepo.CreateUser(user); err != nil {
+		return nil, fmt.Errorf("erreur lors de la création de l'utilisateur: %w", err)
+	}
+	return user, nil
+}
+
+// GetUserByName récupère un utilisateur par son nom.
+// Retourne ErrUserNotFound s'il n'existe pas.
+func (s *UserService) GetUserByName(name string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByName(strings.TrimSpace(name))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &customerrors.ErrUserNotFound{Name: name}
+		}
+		return nil, fmt.Errorf("erreur lors de la récupération de l'utilisateur: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers retourne tous les utilisateurs, triés par nom.
+func (s *UserService) ListUsers() ([]models.User, error) {
+	users, err := s.userRepo.ListUsers()
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des utilisateurs: %w", err)
+	}
+	return users, nil
+}
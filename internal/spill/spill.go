@@ -0,0 +1,244 @@
+package spill
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/api"
+)
+
+// Les événements de clic qui ne peuvent pas être traités en mémoire (channel plein,
+// arrêt du serveur, échec d'écriture en base) sont ajoutés à un journal de segments
+// sur disque. Chaque segment est un fichier JSON lines ("une ligne = un événement"),
+// synchronisé sur disque (fsync) à chaque ajout. Au démarrage suivant, les segments
+// existants sont rejoués puis supprimés.
+
+const (
+	segmentPrefix = "clicks-"
+	segmentExt    = ".jsonl"
+
+	// maxSegmentBytes est la taille au-delà de laquelle un nouveau segment est ouvert,
+	// pour qu'un rejeu ne charge jamais un fichier démesuré en mémoire.
+	maxSegmentBytes = 16 << 20
+
+	// maxLineBytes est la taille maximale d'une ligne relue ; au-delà elle est ignorée.
+	maxLineBytes = 1 << 20
+)
+
+// Queue est un journal de segments sur disque pour les événements de clic.
+// Elle est sûre pour un usage concurrent et implémente api.ClickEventSpiller.
+type Queue struct {
+	dir string
+
+	mu      sync.Mutex
+	file    *os.File // Segment courant, ouvert au premier ajout
+	size    int64    // Taille du segment courant
+	seq     int      // Numéro du prochain segment créé par ce processus
+	pending []string // Segments présents à l'ouverture, à rejouer
+}
+
+// Open ouvre (et crée si besoin) le répertoire de spill et recense les segments
+// laissés par une exécution précédente. Seuls ces segments seront rejoués par Replay :
+// ceux écrits par le processus courant ne le seront qu'au prochain démarrage.
+func Open(dir string) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("impossible de créer le répertoire de spill %s : %w", dir, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("impossible de lire le répertoire de spill %s : %w", dir, err)
+	}
+	var pending []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		pending = append(pending, filepath.Join(dir, name))
+	}
+	// Les noms de segments commencent par un horodatage : l'ordre lexical est l'ordre d'écriture.
+	sort.Strings(pending)
+
+	return &Queue{dir: dir, pending: pending}, nil
+}
+
+// Append ajoute des événements au segment courant et les synchronise sur disque
+// avant de rendre la main : un événement ajouté sans erreur survit à un crash.
+func (q *Queue) Append(events ...api.ClickEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var buf []byte
+	for _, ev := range events {
+		line, err := json.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("impossible d'encoder l'événement de clic : %w", err)
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.file == nil || q.size >= maxSegmentBytes {
+		if err := q.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := q.file.Write(buf)
+	q.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("impossible d'écrire dans le segment %s : %w", q.file.Name(), err)
+	}
+	if err := q.file.Sync(); err != nil {
+		return fmt.Errorf("impossible de synchroniser le segment %s : %w", q.file.Name(), err)
+	}
+	return nil
+}
+
+// rotate ferme le segment courant et en ouvre un nouveau. Doit être appelée avec q.mu verrouillé.
+func (q *Queue) rotate() error {
+	if q.file != nil {
+		if err := q.file.Close(); err != nil {
+			log.Printf("spill: failed to close segment %s: %v", q.file.Name(), err)
+		}
+		q.file = nil
+	}
+
+	name := fmt.Sprintf("%s%s-%d-%06d%s", segmentPrefix, time.Now().UTC().Format("20060102T150405.000000000"),
+		os.Getpid(), q.seq, segmentExt)
+	q.seq++
+
+	f, err := os.OpenFile(filepath.Join(q.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("impossible de créer le segment de spill %s : %w", name, err)
+	}
+	q.file = f
+	q.size = 0
+	return nil
+}
+
+// Replay relit les segments laissés par l'exécution précédente, du plus ancien au plus récent,
+// et passe les événements de chaque segment à handle. Un segment n'est supprimé qu'une fois
+// handle terminé sans erreur ; en cas d'erreur, le rejeu s'arrête et les segments restants
+// sont conservés pour le prochain démarrage. La livraison est donc "au moins une fois".
+// Retourne le nombre d'événements rejoués avec succès.
+func (q *Queue) Replay(handle func(events []api.ClickEvent) error) (int, error) {
+	q.mu.Lock()
+	pending := q.pending
+	q.pending = nil
+	q.mu.Unlock()
+
+	replayed := 0
+	for i, path := range pending {
+		events, err := readSegment(path)
+		if err != nil {
+			q.requeue(pending[i:])
+			return replayed, err
+		}
+		if len(events) > 0 {
+			if err := handle(events); err != nil {
+				q.requeue(pending[i:])
+				return replayed, fmt.Errorf("échec du rejeu du segment %s : %w", path, err)
+			}
+		}
+		if err := os.Remove(path); err != nil {
+			// Le segment a été traité : le conserver provoquerait des doublons au prochain démarrage.
+			q.requeue(pending[i+1:])
+			return replayed + len(events), fmt.Errorf("impossible de supprimer le segment rejoué %s : %w", path, err)
+		}
+		replayed += len(events)
+	}
+	return replayed, nil
+}
+
+// requeue remet des segments non rejoués en tête de la liste d'attente.
+func (q *Queue) requeue(paths []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(append([]string(nil), paths...), q.pending...)
+}
+
+// readSegment lit tous les événements d'un segment. Une ligne illisible (typiquement
+// la dernière ligne d'un segment interrompu par un crash) ou trop longue est ignorée
+// avec un log : elle ne doit pas bloquer le rejeu du reste du segment.
+func readSegment(path string) ([]api.ClickEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("impossible d'ouvrir le segment %s : %w", path, err)
+	}
+	defer f.Close()
+
+	var events []api.ClickEvent
+	reader := bufio.NewReaderSize(f, 64*1024)
+	line := 0
+	for {
+		data, size, err := readLine(reader)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("impossible de lire le segment %s : %w", path, err)
+		}
+		if size > 0 {
+			line++
+			if size > maxLineBytes {
+				log.Printf("spill: skipping oversized line %d in %s (%d bytes)", line, path, size)
+			} else if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+				var ev api.ClickEvent
+				if err := json.Unmarshal(trimmed, &ev); err != nil {
+					log.Printf("spill: skipping corrupt line %d in %s: %v", line, path, err)
+				} else {
+					events = append(events, ev)
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return events, nil
+}
+
+// readLine lit la prochaine ligne de reader et retourne son contenu et sa taille. Une ligne
+// plus longue que maxLineBytes n'est pas conservée : la suite est lue par blocs et jetée,
+// et seule sa taille est retournée (data vaut nil).
+func readLine(reader *bufio.Reader) (data []byte, size int, err error) {
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		size += len(chunk)
+		if size <= maxLineBytes {
+			data = append(data, chunk...)
+		} else {
+			data = nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return data, size, err
+		}
+	}
+}
+
+// Close ferme le segment courant. Les événements déjà ajoutés sont sur disque.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.file == nil {
+		return nil
+	}
+	err := q.file.Close()
+	q.file = nil
+	return err
+}
@@ -0,0 +1,239 @@
+package spill
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/api"
+)
+
+// testEvent retourne un événement de clic distinct pour chaque i.
+func testEvent(i int) api.ClickEvent {
+	return api.ClickEvent{
+		LinkID:    uint(i + 1),
+		ShortCode: fmt.Sprintf("code%d", i),
+		Timestamp: time.Date(2025, 6, 1, 12, 0, i, 0, time.UTC),
+		UserAgent: "Mozilla/5.0",
+		IP:        "203.0.113.7",
+		Referrer:  "https://example.com/",
+	}
+}
+
+// segments retourne les segments présents dans dir.
+func segments(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, segmentPrefix+"*"+segmentExt))
+	if err != nil {
+		t.Fatalf("liste des segments: %v", err)
+	}
+	return paths
+}
+
+// replayAll rouvre dir, comme au démarrage suivant, et retourne les événements rejoués.
+func replayAll(t *testing.T, dir string) []api.ClickEvent {
+	t.Helper()
+	q, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer q.Close()
+
+	var got []api.ClickEvent
+	n, err := q.Replay(func(events []api.ClickEvent) error {
+		got = append(got, events...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if n != len(got) {
+		t.Errorf("Replay retourne %d, %d événement(s) reçu(s)", n, len(got))
+	}
+	return got
+}
+
+// writeSegment écrit content dans un segment de dir, comme l'aurait fait une exécution précédente.
+func writeSegment(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, segmentPrefix+"20250601T120000.000000000-1-000000"+segmentExt)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("écriture du segment: %v", err)
+	}
+}
+
+// eventLine encode ev comme une ligne de segment.
+func eventLine(t *testing.T, ev api.ClickEvent) string {
+	t.Helper()
+	dir := t.TempDir()
+	q, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := q.Append(ev); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	q.Close()
+	data, err := os.ReadFile(segments(t, dir)[0])
+	if err != nil {
+		t.Fatalf("lecture du segment: %v", err)
+	}
+	return string(data)
+}
+
+func TestAppendCloseReplay(t *testing.T) {
+	dir := t.TempDir()
+	q, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := q.Append(testEvent(0), testEvent(1)); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := q.Append(testEvent(2)); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	// Les événements écrits par le processus courant ne sont rejoués qu'au prochain démarrage.
+	n, err := q.Replay(func([]api.ClickEvent) error {
+		t.Error("rejeu inattendu des segments du processus courant")
+		return nil
+	})
+	if err != nil || n != 0 {
+		t.Errorf("Replay = %d, %v ; attendu 0, nil", n, err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got := replayAll(t, dir)
+	if len(got) != 3 {
+		t.Fatalf("%d événement(s) rejoué(s), attendu 3", len(got))
+	}
+	for i, ev := range got {
+		want := testEvent(i)
+		if ev.LinkID != want.LinkID || ev.ShortCode != want.ShortCode || !ev.Timestamp.Equal(want.Timestamp) ||
+			ev.UserAgent != want.UserAgent || ev.IP != want.IP || ev.Referrer != want.Referrer {
+			t.Errorf("événement %d = %+v, attendu %+v", i, ev, want)
+		}
+	}
+	if left := segments(t, dir); len(left) != 0 {
+		t.Errorf("segments non supprimés après rejeu : %v", left)
+	}
+}
+
+func TestReplaySkipsTruncatedLastLine(t *testing.T) {
+	dir := t.TempDir()
+	line := eventLine(t, testEvent(0))
+	// Crash au milieu de l'écriture de la seconde ligne.
+	writeSegment(t, dir, line+line[:len(line)/2])
+
+	got := replayAll(t, dir)
+	if len(got) != 1 || got[0].LinkID != 1 {
+		t.Errorf("événements rejoués = %+v, attendu le seul événement complet", got)
+	}
+}
+
+func TestReplaySkipsOversizedLine(t *testing.T) {
+	dir := t.TempDir()
+	line := eventLine(t, testEvent(0))
+	oversized := `{"UserAgent":"` + strings.Repeat("a", maxLineBytes) + "\"}\n"
+	writeSegment(t, dir, line+oversized+line)
+
+	got := replayAll(t, dir)
+	if len(got) != 2 {
+		t.Errorf("%d événement(s) rejoué(s), attendu 2 : seule la ligne trop longue est ignorée", len(got))
+	}
+}
+
+func TestReadLineDiscardsOversizedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "segment"+segmentExt)
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 3*maxLineBytes)+"\n"), 0o644); err != nil {
+		t.Fatalf("écriture: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("ouverture: %v", err)
+	}
+	defer f.Close()
+
+	data, size, err := readLine(bufio.NewReaderSize(f, 64*1024))
+	if err != nil {
+		t.Fatalf("readLine: %v", err)
+	}
+	if size != 3*maxLineBytes+1 {
+		t.Errorf("taille = %d, attendu %d", size, 3*maxLineBytes+1)
+	}
+	if data != nil {
+		t.Errorf("%d octet(s) conservé(s) pour une ligne trop longue, attendu aucun", len(data))
+	}
+}
+
+func TestAppendRotatesSegments(t *testing.T) {
+	dir := t.TempDir()
+	q, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	// Un premier lot qui dépasse maxSegmentBytes : l'ajout suivant ouvre un nouveau segment.
+	big := testEvent(0)
+	big.UserAgent = strings.Repeat("u", 1000)
+	batch := make([]api.ClickEvent, maxSegmentBytes/1000+1)
+	for i := range batch {
+		batch[i] = big
+	}
+	if err := q.Append(batch...); err != nil {
+		t.Fatalf("Append du premier lot: %v", err)
+	}
+	if err := q.Append(testEvent(1)); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	q.Close()
+
+	if n := len(segments(t, dir)); n != 2 {
+		t.Fatalf("%d segment(s), attendu 2", n)
+	}
+	got := replayAll(t, dir)
+	if len(got) != len(batch)+1 {
+		t.Errorf("%d événement(s) rejoué(s), attendu %d", len(got), len(batch)+1)
+	}
+	if last := got[len(got)-1]; last.LinkID != 2 {
+		t.Errorf("dernier événement rejoué = %+v, attendu l'événement du second segment", last)
+	}
+	if left := segments(t, dir); len(left) != 0 {
+		t.Errorf("segments non supprimés après rejeu : %v", left)
+	}
+}
+
+func TestReplayKeepsSegmentsOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeSegment(t, dir, eventLine(t, testEvent(0)))
+
+	q, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer q.Close()
+
+	if _, err := q.Replay(func([]api.ClickEvent) error { return errors.New("base indisponible") }); err == nil {
+		t.Fatal("Replay: erreur du traitement non remontée")
+	}
+	if n := len(segments(t, dir)); n != 1 {
+		t.Fatalf("%d segment(s) après un échec, attendu 1", n)
+	}
+
+	// Le segment conservé est rejoué à la tentative suivante.
+	n, err := q.Replay(func([]api.ClickEvent) error { return nil })
+	if err != nil || n != 1 {
+		t.Errorf("second Replay = %d, %v ; attendu 1, nil", n, err)
+	}
+	if n := len(segments(t, dir)); n != 0 {
+		t.Errorf("%d segment(s) après rejeu, attendu 0", n)
+	}
+}
@@ -3,56 +3,196 @@ package workers
 import (
 	"context"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/axellelanca/urlshortener/internal/analytics"
 	"github.com/axellelanca/urlshortener/internal/api"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/spill"
 )
 
+// Valeurs par défaut du regroupement des clics, utilisées si la configuration est absente ou invalide.
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 500 * time.Millisecond
+)
+
+// ClickWorkerOptions regroupe les paramètres des workers de clics.
+type ClickWorkerOptions struct {
+	WorkerCount   int                      // Nombre de goroutines workers
+	BatchSize     int                      // Nombre maximum de clics écrits en une seule transaction
+	FlushInterval time.Duration            // Délai maximum entre la réception d'un clic et son écriture
+	BotClassifier *analytics.BotClassifier // Identifie les clics de robots
+	Spill         api.ClickEventSpiller    // Reçoit les lots qui n'ont pas pu être écrits en base (optionnel)
+}
+
+// ClickWorkerPool est le handle retourné par StartClickWorkers.
+// Il permet d'attendre la fin des workers et de connaître le devenir des événements traités.
+type ClickWorkerPool struct {
+	wg        sync.WaitGroup
+	persisted atomic.Int64 // Événements écrits en base
+	spilled   atomic.Int64 // Événements confiés au journal sur disque après un échec d'écriture
+	lost      atomic.Int64 // Événements ni écrits en base ni sur disque
+}
+
+// ClickWorkerStats résume le devenir des événements traités par un ClickWorkerPool.
+type ClickWorkerStats struct {
+	Persisted int64
+	Spilled   int64
+	Lost      int64
+}
+
+// Wait bloque jusqu'à ce que tous les workers se soient arrêtés (channel fermé ou contexte annulé),
+// ou jusqu'à l'expiration de ctx, auquel cas l'erreur du contexte est retournée.
+func (p *ClickWorkerPool) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats retourne les compteurs du pool depuis son démarrage.
+func (p *ClickWorkerPool) Stats() ClickWorkerStats {
+	return ClickWorkerStats{
+		Persisted: p.persisted.Load(),
+		Spilled:   p.spilled.Load(),
+		Lost:      p.lost.Load(),
+	}
+}
+
 // clickWorker consomme des api.ClickEvent depuis le channel et les persiste en base via clickRepo.
-// Il écoute le contexte pour un arrêt propre.
-func clickWorker(ctx context.Context, id int, in <-chan api.ClickEvent, clickRepo repository.ClickRepository) {
+// Les événements sont regroupés en lots, écrits en une seule insertion multi-lignes lorsque
+// le lot atteint opts.BatchSize ou, au plus tard, toutes les opts.FlushInterval.
+// Pour un arrêt propre, le caller ferme le channel : le worker écrit alors tout ce qui reste
+// avant de s'arrêter. L'annulation du contexte l'arrête plus tôt, après écriture du lot en cours
+// (les événements restés dans le channel sont alors à la charge du caller).
+// Un lot dont l'écriture échoue est confié à opts.Spill pour être rejoué au prochain démarrage.
+func (p *ClickWorkerPool) clickWorker(ctx context.Context, id int, in <-chan api.ClickEvent, clickRepo repository.ClickRepository, opts ClickWorkerOptions) {
+	defer p.wg.Done()
 	log.Printf("clickWorker %d: started", id)
 	defer log.Printf("clickWorker %d: stopped", id)
 
+	batch := make([]api.ClickEvent, 0, opts.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// Tenter de persister le lot (log et continue en cas d'erreur : on ne veut pas bloquer le worker)
+		if err := clickRepo.CreateClicks(newClicks(batch, opts.BotClassifier)); err != nil {
+			log.Printf("clickWorker %d: failed to persist batch of %d click(s): %v", id, len(batch), err)
+			if spillBatch(id, batch, opts.Spill) {
+				p.spilled.Add(int64(len(batch)))
+			} else {
+				p.lost.Add(int64(len(batch)))
+			}
+		} else {
+			log.Printf("clickWorker %d: persisted batch of %d click(s)", id, len(batch))
+			p.persisted.Add(int64(len(batch)))
+		}
+		batch = make([]api.ClickEvent, 0, opts.BatchSize)
+	}
+
+	ticker := time.NewTicker(opts.FlushInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
+			flush()
 			return
 		case ev, ok := <-in:
 			if !ok {
 				// channel fermé
+				flush()
 				return
 			}
-
-			// Convertir l'événement en modèle GORM Click
-			click := &models.Click{
-				LinkID:    ev.LinkID,
-				Timestamp: ev.Timestamp,
-				UserAgent: ev.UserAgent,
-				IPAddress: ev.IP,
+			batch = append(batch, ev)
+			if len(batch) >= opts.BatchSize {
+				flush()
 			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
 
-			// Tenter de persister le clic
-			if err := clickRepo.CreateClick(click); err != nil {
-				// Log et continue (on ne veut pas bloquer le worker sur une erreur)
-				log.Printf("clickWorker %d: failed to persist click for link %d: %v", id, ev.LinkID, err)
-			} else {
-				log.Printf("clickWorker %d: persisted click for link %d", id, ev.LinkID)
-			}
+// spillBatch confie à spill un lot qui n'a pas pu être écrit en base.
+// Retourne false si le lot est perdu.
+func spillBatch(id int, batch []api.ClickEvent, spill api.ClickEventSpiller) bool {
+	if spill == nil {
+		log.Printf("clickWorker %d: no spill configured, %d click(s) lost", id, len(batch))
+		return false
+	}
+	if err := spill.Append(batch...); err != nil {
+		log.Printf("clickWorker %d: failed to spill batch of %d click(s), events lost: %v", id, len(batch), err)
+		return false
+	}
+	log.Printf("clickWorker %d: spilled batch of %d click(s) to disk", id, len(batch))
+	return true
+}
 
-			// Petite pause pour éviter hot-loop si nécessaire (configurable si besoin)
-			time.Sleep(5 * time.Millisecond)
-		}
+// newClicks convertit un lot d'événements en modèles GORM Click.
+func newClicks(events []api.ClickEvent, botClassifier *analytics.BotClassifier) []*models.Click {
+	clicks := make([]*models.Click, len(events))
+	for i, ev := range events {
+		clicks[i] = newClick(ev, botClassifier)
+	}
+	return clicks
+}
+
+// newClick convertit un événement en modèle GORM Click, en extrayant les dimensions
+// du User-Agent, l'hôte du référent et la détection des robots.
+func newClick(ev api.ClickEvent, botClassifier *analytics.BotClassifier) *models.Click {
+	ua := analytics.ParseUserAgent(ev.UserAgent)
+	return &models.Click{
+		LinkID:    ev.LinkID,
+		Timestamp: ev.Timestamp,
+		UserAgent: ev.UserAgent,
+		IPAddress: ev.IP,
+		Browser:   ua.Browser,
+		OS:        ua.OS,
+		Device:    ua.Device,
+		Referrer:  analytics.NormalizeReferrer(ev.Referrer),
+		IsBot:     ua.Device == analytics.DeviceBot || botClassifier.IsBot(ev.UserAgent, ev.IP),
 	}
 }
 
-// StartClickWorkers démarre n workers et retourne immédiatement.
-// Le caller doit fournir un contexte annulable pour gérer l'arrêt propre.
-func StartClickWorkers(ctx context.Context, n int, in <-chan api.ClickEvent, clickRepo repository.ClickRepository) {
-	log.Printf("Starting %d click worker(s)...", n)
-	for i := 0; i < n; i++ {
-		go clickWorker(ctx, i, in, clickRepo)
+// StartClickWorkers démarre opts.WorkerCount workers et retourne immédiatement un handle
+// permettant d'attendre leur arrêt. Pour un arrêt propre, fermer le channel puis appeler Wait.
+func StartClickWorkers(ctx context.Context, in <-chan api.ClickEvent, clickRepo repository.ClickRepository, opts ClickWorkerOptions) *ClickWorkerPool {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
 	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+
+	log.Printf("Starting %d click worker(s) (batch size %d, flush interval %v)...", opts.WorkerCount, opts.BatchSize, opts.FlushInterval)
+	pool := &ClickWorkerPool{}
+	for i := 0; i < opts.WorkerCount; i++ {
+		pool.wg.Add(1)
+		go pool.clickWorker(ctx, i, in, clickRepo, opts)
+	}
+	return pool
+}
+
+// ReplaySpill écrit en base les événements laissés sur disque par l'exécution précédente
+// (surcharge du channel, arrêt du serveur, échec d'écriture). Chaque segment est écrit
+// en une transaction puis supprimé. À appeler avant de démarrer les workers.
+// Retourne le nombre d'événements rejoués.
+func ReplaySpill(queue *spill.Queue, clickRepo repository.ClickRepository, botClassifier *analytics.BotClassifier) (int, error) {
+	return queue.Replay(func(events []api.ClickEvent) error {
+		return clickRepo.CreateClicks(newClicks(events, botClassifier))
+	})
 }
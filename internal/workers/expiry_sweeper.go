@@ -0,0 +1,46 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// StartExpirySweeper lance une goroutine qui marque périodiquement comme expirés
+// les liens dont la date d'expiration est dépassée (expiration "douce").
+// La redirection vérifie déjà la date elle-même : le sweeper sert surtout à tenir
+// la colonne 'expired' à jour pour les listes et le moniteur.
+// Le caller doit fournir un contexte annulable pour gérer l'arrêt propre.
+func StartExpirySweeper(ctx context.Context, linkRepo repository.LinkRepository, interval time.Duration) {
+	go func() {
+		log.Printf("expirySweeper: started (interval %v)", interval)
+		defer log.Printf("expirySweeper: stopped")
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			sweepExpiredLinks(linkRepo)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sweepExpiredLinks effectue un passage du sweeper et loggue le nombre de liens expirés.
+func sweepExpiredLinks(linkRepo repository.LinkRepository) {
+	count, err := linkRepo.ExpireLinks(time.Now().UTC())
+	if err != nil {
+		log.Printf("expirySweeper: failed to expire links: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("expirySweeper: %d link(s) marked as expired", count)
+	}
+}